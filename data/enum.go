@@ -12,6 +12,15 @@ type Enum struct {
 	// in Typescript, it's better to use string representation of it.
 	// So Values here will basically be the name of the field.
 	Values []string
+	// Namespace is the TypeScript namespace the enum is rendered in, empty for the top level
+	Namespace string
+	// Location is where the enum is defined in the proto
+	Location *Location
+}
+
+// Identifier returns the identifier to reference the enum from the top level of the file
+func (e *Enum) Identifier() string {
+	return qualifyName(e.Namespace, e.Name)
 }
 
 // NewEnum creates an enum instance.
@@ -0,0 +1,17 @@
+package data
+
+// FetchModule stores the information to render the fetch module, which only includes the features used by the generated files
+type FetchModule struct {
+	// EnableStylingCheck enables the styling check for the fetch module
+	EnableStylingCheck bool
+	// NeedsBase64 indicates bytes fields are present and need to be encoded with base64
+	NeedsBase64 bool
+	// NeedsServerStreaming indicates server streaming methods are present
+	NeedsServerStreaming bool
+	// NeedsURLSearchParams indicates GET methods are present and need to render the request into the query string
+	NeedsURLSearchParams bool
+	// NeedsLongRunningOperation indicates methods returning long-running operations are present
+	NeedsLongRunningOperation bool
+	// NeedsFieldMask indicates the field mask paths are generated and the field mask helper is needed
+	NeedsFieldMask bool
+}
@@ -11,6 +11,8 @@ import (
 type File struct {
 	// Dependencies is a list of dependencies for the file, which will be rendered at the top of the file as import statements
 	Dependencies []*Dependency
+	// ModuleIdentifiers stores the identifiers the dependencies are imported as, keyed by the proto file name of the dependency
+	ModuleIdentifiers map[string]string
 	// Enums is a list of enums to render, due to the fact that there cannot be any enum defined nested in the class in Typescript.
 	// All Enums will be rendered at the top level
 	Enums []*Enum
@@ -22,12 +24,28 @@ type File struct {
 	Services Services
 	// Name is the name of the file
 	Name string
+	// Package is the proto package of the file
+	Package string
 	// TSFileName is the name of the output file
 	TSFileName string
 	// PackageNonScalarType stores the type inside the same packages within the file, which will be used to figure out external dependencies inside the same package (different files)
 	PackageNonScalarType []Type
 	// EnableStylingCheck enables the styling check for the given file
 	EnableStylingCheck bool
+	// FetchModuleAPIVersion is the API version the fetch module package imported by the file is checked against
+	// when type checking, empty if the fetch module is generated
+	FetchModuleAPIVersion string
+	// Client is the file the services are rendered into when they are separated from the types, nil otherwise
+	Client *File
+}
+
+// OutputFiles returns the files rendered for the proto file, which are the file itself and the client file if there's one
+func (f *File) OutputFiles() []*File {
+	if f.Client == nil {
+		return []*File{f}
+	}
+
+	return []*File{f, f.Client}
 }
 
 // StableDependencies are dependencies in a stable order.
@@ -59,6 +77,89 @@ func (f *File) TrackPackageNonScalarType(t Type) {
 	}
 }
 
+// ModuleIdentifier returns the identifier the proto file is imported as inside the file,
+// it falls back to the module name if the proto file has not been allocated an identifier
+func (f *File) ModuleIdentifier(packageName, fileName string) string {
+	if identifier, ok := f.ModuleIdentifiers[fileName]; ok {
+		return identifier
+	}
+
+	return GetModuleName(packageName, fileName)
+}
+
+// RootEnums returns the enums rendered at the top level of the file
+func (f *File) RootEnums() []*Enum {
+	out := make([]*Enum, 0, len(f.Enums))
+	for _, e := range f.Enums {
+		if e.Namespace == "" {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+// RootMessages returns the messages rendered at the top level of the file
+func (f *File) RootMessages() []*Message {
+	out := make([]*Message, 0, len(f.Messages))
+	for _, m := range f.Messages {
+		if m.Namespace == "" {
+			out = append(out, m)
+		}
+	}
+
+	return out
+}
+
+// Namespace groups the nested enums and messages rendered inside the same TypeScript namespace
+type Namespace struct {
+	// Name is the dotted name of the namespace, e.g. Foo.Bar
+	Name string
+	// Enums are the enums inside the namespace
+	Enums []*Enum
+	// Messages are the messages inside the namespace
+	Messages []*Message
+}
+
+// Namespaces returns the namespaces in the order they first appear in the file
+func (f *File) Namespaces() []*Namespace {
+	out := make([]*Namespace, 0)
+	namespaces := make(map[string]*Namespace)
+	get := func(name string) *Namespace {
+		ns, ok := namespaces[name]
+		if !ok {
+			ns = &Namespace{Name: name}
+			namespaces[name] = ns
+			out = append(out, ns)
+		}
+		return ns
+	}
+
+	for _, e := range f.Enums {
+		if e.Namespace != "" {
+			ns := get(e.Namespace)
+			ns.Enums = append(ns.Enums, e)
+		}
+	}
+
+	for _, m := range f.Messages {
+		if m.Namespace != "" {
+			ns := get(m.Namespace)
+			ns.Messages = append(ns.Messages, m)
+		}
+	}
+
+	return out
+}
+
+func qualifyName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+
+	return namespace + "." + name
+}
+
 func (f *File) IsEmpty() bool {
 	return len(f.Enums) == 0 && len(f.Messages) == 0 && len(f.Services) == 0
 }
@@ -67,6 +168,7 @@ func (f *File) IsEmpty() bool {
 func NewFile() *File {
 	return &File{
 		Dependencies:           make([]*Dependency, 0),
+		ModuleIdentifiers:      make(map[string]string),
 		Enums:                  make([]*Enum, 0),
 		Messages:               make([]*Message, 0),
 		Services:               make([]*Service, 0),
@@ -83,6 +185,12 @@ type Dependency struct {
 	ModuleIdentifier string
 	// Source file will be the file at the end of the import statement.
 	SourceFile string
+	// TypeOnly indicates the module is only referenced in type positions, it will be imported with import type
+	// so that the import is erased from the compiled code and doesn't create runtime import cycles
+	TypeOnly bool
+	// Runtime indicates the module is referenced by the generated code at runtime rather than by the types only,
+	// which is the case for the fetch module. only these are imported in the generated JavaScript files
+	Runtime bool
 }
 
 // GetModuleName returns module name = package name + file name to be the unique identifier for source file in a ts file
@@ -98,7 +206,7 @@ func GetModuleName(packageName, fileName string) string {
 		}
 	}
 
-	return strings.Join(packageParts, "") + strings.ToUpper(name[:1]) + name[1:]
+	return EscapeIdentifier(strings.Join(packageParts, "") + strings.ToUpper(name[:1]) + name[1:])
 }
 
 // GetTSFileName gets the typescript filename out of the proto file name
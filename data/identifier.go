@@ -0,0 +1,71 @@
+package data
+
+import "regexp"
+
+// reservedWords are the words that cannot be used as identifiers in TypeScript,
+// including the strict mode reserved words and the names of the built in types
+var reservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+	"if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"implements": true, "interface": true, "let": true, "package": true, "private": true,
+	"protected": true, "public": true, "static": true, "yield": true, "await": true,
+	"arguments": true, "eval": true,
+	"any": true, "bigint": true, "boolean": true, "never": true, "number": true, "object": true,
+	"string": true, "symbol": true, "undefined": true, "unknown": true,
+}
+
+// globalNames are the global names that are either referenced by the generated code
+// or commonly expected to keep their global meaning, declaring them would shadow the globals
+var globalNames = map[string]bool{
+	"Array": true, "ArrayLike": true, "Boolean": true, "Date": true, "Error": true, "Exclude": true,
+	"Function": true, "JSON": true, "Map": true, "Math": true, "Number": true, "Object": true,
+	"Partial": true, "Promise": true, "Record": true, "RequestInit": true, "Set": true,
+	"String": true, "Symbol": true, "Uint8Array": true, "URLSearchParams": true,
+	// helper types declared in the generated files and the fetch module alias
+	"Absent": true, "OneOf": true, "fm": true, "_FetchModuleAPIVersion": true,
+}
+
+// staticMemberNames are the names of the built in properties of a class, which static methods cannot override
+var staticMemberNames = map[string]bool{
+	"arguments": true, "caller": true, "constructor": true, "length": true, "name": true, "prototype": true,
+}
+
+// prototypeNames are the property names with a special meaning on every object
+var prototypeNames = map[string]bool{
+	"constructor": true, "__proto__": true,
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// EscapeIdentifier escapes the name with a trailing underscore if it's either a reserved word or
+// clashes with the global names the generated code relies on
+func EscapeIdentifier(name string) string {
+	if reservedWords[name] || globalNames[name] {
+		return name + "_"
+	}
+
+	return name
+}
+
+// EscapeStaticMemberName escapes the name of a static class member if it clashes with the built in properties
+func EscapeStaticMemberName(name string) string {
+	if staticMemberNames[name] {
+		return name + "_"
+	}
+
+	return name
+}
+
+// QuotePropertyName quotes the property or enum member name if it's a reserved word, a special property
+// of objects or not a valid identifier, the name is kept as is in the JSON payload either way
+func QuotePropertyName(name string) string {
+	if reservedWords[name] || prototypeNames[name] || !identifierPattern.MatchString(name) {
+		return `"` + name + `"`
+	}
+
+	return name
+}
@@ -0,0 +1,57 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"Book":        "Book",
+		"class":       "class_",
+		"delete":      "delete_",
+		"string":      "string_",
+		"Object":      "Object_",
+		"Promise":     "Promise_",
+		"Uint8Array":  "Uint8Array_",
+		"OneOf":       "OneOf_",
+		"fm":          "fm_",
+		"constructor": "constructor",
+	}
+
+	for name, expected := range tests {
+		assert.Equal(t, expected, EscapeIdentifier(name), name)
+	}
+}
+
+func TestEscapeStaticMemberName(t *testing.T) {
+	tests := map[string]string{
+		"GetBook":   "GetBook",
+		"Name":      "Name",
+		"name":      "name_",
+		"length":    "length_",
+		"prototype": "prototype_",
+	}
+
+	for name, expected := range tests {
+		assert.Equal(t, expected, EscapeStaticMemberName(name), name)
+	}
+}
+
+func TestQuotePropertyName(t *testing.T) {
+	tests := map[string]string{
+		"title":       "title",
+		"_id":         "_id",
+		"delete":      `"delete"`,
+		"constructor": `"constructor"`,
+		"__proto__":   `"__proto__"`,
+		"1st":         `"1st"`,
+		"foo-bar":     `"foo-bar"`,
+		"Object":      "Object",
+	}
+
+	for name, expected := range tests {
+		assert.Equal(t, expected, QuotePropertyName(name), name)
+	}
+}
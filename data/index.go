@@ -0,0 +1,13 @@
+package data
+
+// IndexFile stores the information to render the barrel index file of a package
+type IndexFile struct {
+	// Package is the proto package the index file is generated for
+	Package string
+	// Name is the name of the output file
+	Name string
+	// Exports are the generated files of the package, which will be re-exported as namespaces
+	Exports []*Dependency
+	// EnableStylingCheck enables the styling check for the given file
+	EnableStylingCheck bool
+}
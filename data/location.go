@@ -0,0 +1,30 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Location tells where an element is defined inside a proto file, so that problems can be reported against it
+type Location struct {
+	// File is the name of the proto file where the element is defined
+	File string
+	// Element is the fully qualified name of the element
+	Element string
+	// Path is the path to the element inside the file descriptor, the same as SourceCodeInfo.Location.Path
+	Path []int32
+	// Line is the 1-based line number of the element, it will be 0 if the file doesn't come with source code info
+	Line int
+	// Column is the 1-based column number of the element, it will be 0 if the file doesn't come with source code info
+	Column int
+}
+
+// String formats the location in the same way protoc reports errors, file:line:column: element
+func (l *Location) String() string {
+	position := l.File
+	if l.Line > 0 {
+		position = fmt.Sprintf("%s:%d:%d", l.File, l.Line, l.Column)
+	}
+
+	return fmt.Sprintf("%s: %s", position, strings.TrimPrefix(l.Element, "."))
+}
@@ -6,6 +6,8 @@ type Message struct {
 	Nested bool
 	// Name is the name of the Message
 	Name string
+	// Namespace is the TypeScript namespace the message is rendered in, empty for the top level
+	Namespace string
 	//FQType is the fully qualified type name for the message itself
 	FQType string
 	// Enums is a list of NestedEnums inside
@@ -20,6 +22,8 @@ type Message struct {
 	OneOfFieldsGroups map[int32][]*Field
 	// OneOfFieldNames is the names of one of fields with same index. so that renderer can render the clearing of other fields on set.
 	OneOfFieldsNames map[int32]string
+	// Location is where the message is defined in the proto
+	Location *Location
 }
 
 // HasOneOfFields returns true when the message has a one of field.
@@ -27,6 +31,11 @@ func (m *Message) HasOneOfFields() bool {
 	return len(m.OneOfFieldsGroups) > 0
 }
 
+// Identifier returns the identifier to reference the message from the top level of the file
+func (m *Message) Identifier() string {
+	return qualifyName(m.Namespace, m.Name)
+}
+
 // NewMessage initialises and return a Message
 func NewMessage() *Message {
 	return &Message{
@@ -58,6 +67,8 @@ type NestedEnum struct {
 // Field stores the information about a field inside message
 type Field struct {
 	Name string
+	// JSONName is the json_name of the field populated by protoc
+	JSONName string
 	// Type will be similar to NestedEnum.Type. Where scalar type and types inside
 	// the same file will be short type
 	// external types will have fully-qualified name and translated during render time
@@ -74,6 +85,8 @@ type Field struct {
 	OneOfIndex int32
 	// IsRepeated indicates whether the field is a repeated field
 	IsRepeated bool
+	// Location is where the field is defined in the proto
+	Location *Location
 }
 
 // GetType returns some information of the type to aid the rendering
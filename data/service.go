@@ -1,11 +1,23 @@
 package data
 
+import "strings"
+
 // Service is the data representation of Service in proto
 type Service struct {
 	// Name is the name of the Service
 	Name string
 	// Methods is a list of methods data
 	Methods []*Method
+	// UnsupportedMethods are the client streaming methods, which are not supported by grpc-gateway and will not be generated
+	UnsupportedMethods []*Method
+	// Location is where the service is defined in the proto
+	Location *Location
+}
+
+// FunctionName returns the name of the standalone function of the service, prefixed by the service name
+// to keep it unique in the file, e.g. SayHello of Greeter becomes greeterSayHello
+func (s *Service) FunctionName(name string) string {
+	return strings.ToLower(s.Name[:1]) + s.Name[1:] + name
 }
 
 // Services is an alias of Service array
@@ -44,7 +56,8 @@ func (s Services) NeedsFetchModule() bool {
 // NewService returns an initialised service
 func NewService() *Service {
 	return &Service{
-		Methods: make([]*Method, 0),
+		Methods:            make([]*Method, 0),
+		UnsupportedMethods: make([]*Method, 0),
 	}
 }
 
@@ -66,6 +79,40 @@ type Method struct {
 	HTTPMethod string
 	// HTTPBody is the path for request body in the body's payload
 	HTTPRequestBody *string
+	// LongRunning stores the operation information if the method returns a google.longrunning.Operation, nil otherwise
+	LongRunning *LongRunningOperation
+	// Signatures are the convenience signatures declared with google.api.method_signature
+	Signatures []*MethodSignature
+	// Location is where the method is defined in the proto
+	Location *Location
+}
+
+// MethodSignature represents a google.api.method_signature which takes request fields positionally
+type MethodSignature struct {
+	// Name is the name of the convenience function, e.g. CreateBookWithParentBook for signature "parent,book" of CreateBook
+	Name string
+	// Params are the request fields in the order they are declared in the signature
+	Params []*SignatureParam
+}
+
+// SignatureParam is a request field taken positionally by a method signature
+type SignatureParam struct {
+	// Name is the name of the parameter, the lowerCamel form of the joined path
+	Name string
+	// Path is the path of the field inside the request, nested fields will have more than one element
+	Path []string
+	// Type is the type of the field
+	Type *MethodArgument
+}
+
+// LongRunningOperation stores the information declared in google.longrunning.operation_info for a method
+type LongRunningOperation struct {
+	// ResponseType is the type the operation resolves to when it's done, nil if it cannot be resolved
+	ResponseType *MethodArgument
+	// MetadataType is the type of the metadata reported while the operation is running, nil if it cannot be resolved
+	MetadataType *MethodArgument
+	// GetOperationURL is the gateway path of google.longrunning.Operations.GetOperation used to poll the operation
+	GetOperationURL string
 }
 
 // MethodArgument stores the type information about method argument
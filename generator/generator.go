@@ -3,10 +3,10 @@ package generator
 import (
 	"bytes"
 	"fmt"
-	"path/filepath"
 	"strings"
 	"text/template"
 
+	descriptorpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
 	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
 	log "github.com/sirupsen/logrus" // nolint: depguard
 
@@ -22,33 +22,94 @@ type TypeScriptGRPCGatewayGenerator struct {
 	// This option will only turn on in integration test to ensure the readability in
 	// the generated code.
 	EnableStylingCheck bool
+	// LintStrict turns the lint issues found in the protos into generation errors instead of warnings
+	LintStrict bool
 }
 
 const (
 	// EnableStylingCheckOption is the option name for EnableStylingCheck
-	EnableStylingCheckOption = "enable_styling_check"
+	EnableStylingCheckOption = registry.EnableStylingCheck
+	// LintStrictOption is the option name for LintStrict
+	LintStrictOption = registry.LintStrict
 )
 
-// New returns an initialised generator
+const (
+	// jsExtension replaces .ts in the names of the javascript files generated with target js
+	jsExtension = ".js"
+	// dtsExtension replaces .ts in the names of the declaration files generated with target js
+	dtsExtension = ".d.ts"
+	// mjsExtension replaces .ts in the names of the javascript files generated with import_extension .mjs
+	mjsExtension = ".mjs"
+	// dmtsExtension replaces .ts in the names of the declaration files generated with import_extension .mjs
+	dmtsExtension = ".d.mts"
+)
+
+// rendering is a file rendered with one of the templates in the template set
+type rendering struct {
+	// template is the name of the template to execute
+	template string
+	// fileName is the name of the generated file
+	fileName string
+	// declaration is whether the file is the declaration file of a javascript file
+	declaration bool
+}
+
+// New returns an initialised generator from the plugin parameters
 func New(paramsMap map[string]string) (*TypeScriptGRPCGatewayGenerator, error) {
-	registry, err := registry.NewRegistry(paramsMap)
+	opts, err := ParseOptions(paramsMap)
 	if err != nil {
-		return nil, errors.Wrap(err, "error instantiating a new registry")
+		return nil, errors.Wrap(err, "error parsing the parameters")
 	}
 
-	enableStylingCheck := false
-	enableStylingCheckVal, ok := paramsMap[EnableStylingCheckOption]
-	if ok {
-		// default to true if not disabled specifi
-		enableStylingCheck = enableStylingCheckVal == "true"
+	return NewFromOptions(*opts)
+}
+
+// NewFromOptions returns an initialised generator from the options
+func NewFromOptions(opts Options) (*TypeScriptGRPCGatewayGenerator, error) {
+	registry, err := registry.NewRegistryFromOptions(opts.Options)
+	if err != nil {
+		return nil, errors.Wrap(err, "error instantiating a new registry")
 	}
 
 	return &TypeScriptGRPCGatewayGenerator{
 		Registry:           registry,
-		EnableStylingCheck: enableStylingCheck,
+		EnableStylingCheck: opts.EnableStylingCheck,
+		LintStrict:         opts.LintStrict,
 	}, nil
 }
 
+// GeneratedFile is a file generated by GenerateFiles
+type GeneratedFile struct {
+	// Name is the path of the file relative to the output directory
+	Name string
+	// Content is the content of the file
+	Content string
+}
+
+// GenerateFiles generates the files for the proto files to generate in the file descriptor set, which needs to
+// contain the files they import as well, e.g. built with protoc --include_imports or parsed by a Go proto parser
+func GenerateFiles(fds *descriptorpb.FileDescriptorSet, files []string, opts Options) ([]*GeneratedFile, error) {
+	g, err := NewFromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := g.Generate(&plugin.CodeGeneratorRequest{
+		FileToGenerate: files,
+		ProtoFile:      fds.GetFile(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	generated := make([]*GeneratedFile, 0, len(resp.GetFile()))
+	for _, f := range resp.GetFile() {
+		generated = append(generated, &GeneratedFile{Name: f.GetName(), Content: f.GetContent()})
+	}
+
+	return generated, nil
+}
+
 // Generate take a code generator request and returns a response. it analyse request with registry and use the generated data to render ts files
 func (t *TypeScriptGRPCGatewayGenerator) Generate(req *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error) {
 	resp := &plugin.CodeGeneratorResponse{}
@@ -57,76 +118,192 @@ func (t *TypeScriptGRPCGatewayGenerator) Generate(req *plugin.CodeGeneratorReque
 	if err != nil {
 		return nil, errors.Wrap(err, "error analysing proto files")
 	}
+
+	err = t.lint(filesData)
+	if err != nil {
+		return nil, err
+	}
+
 	tmpl := GetTemplate(t.Registry)
 	log.Debugf("files to generate %v", req.GetFileToGenerate())
 
 	needToGenerateFetchModule := false
 	// feed fileData into rendering process
 	for _, fileData := range filesData {
-		fileData.EnableStylingCheck = t.EnableStylingCheck
-		if !t.Registry.IsFileToGenerate(fileData.Name) {
+		if !t.Registry.IsFileToGenerate(fileData.Name) && !t.Registry.IsDependencyToGenerate(fileData.Name) {
 			log.Debugf("file %s is not the file to generate, skipping", fileData.Name)
 			continue
 		}
 
-		log.Debugf("generating file for %s", fileData.TSFileName)
-		generated, err := t.generateFile(fileData, tmpl)
+		for _, outputFile := range fileData.OutputFiles() {
+			outputFile.EnableStylingCheck = t.EnableStylingCheck
+			log.Debugf("generating file for %s", outputFile.TSFileName)
+			generated, err := t.generateFile(outputFile, tmpl)
+			if err != nil {
+				return nil, errors.Wrap(err, "error generating file")
+			}
+			resp.File = append(resp.File, generated...)
+			needToGenerateFetchModule = needToGenerateFetchModule || outputFile.Services.NeedsFetchModule()
+		}
+	}
+
+	if t.Registry.GenerateIndexFiles {
+		indexTmpl := GetIndexTemplate()
+		indexFiles, err := t.Registry.AnalyseIndexFiles(filesData)
 		if err != nil {
-			return nil, errors.Wrap(err, "error generating file")
+			return nil, errors.Wrap(err, "error analysing index files")
+		}
+
+		for _, indexFile := range indexFiles {
+			log.Debugf("generating index file %s for package %s", indexFile.Name, indexFile.Package)
+			indexFile.EnableStylingCheck = t.EnableStylingCheck
+			generated, err := t.generateIndexFile(indexFile, indexTmpl)
+			if err != nil {
+				return nil, errors.Wrap(err, "error generating index file")
+			}
+			resp.File = append(resp.File, generated...)
 		}
-		resp.File = append(resp.File, generated)
-		needToGenerateFetchModule = needToGenerateFetchModule || fileData.Services.NeedsFetchModule()
 	}
 
-	if needToGenerateFetchModule {
+	if needToGenerateFetchModule && t.Registry.FetchModulePackage == "" {
 		// generate fetch module
 		fetchTmpl := GetFetchModuleTemplate()
 		log.Debugf("generate fetch template")
-		generatedFetch, err := t.generateFetchModule(fetchTmpl)
+		generatedFetch, err := t.generateFetchModule(t.Registry.AnalyseFetchModule(filesData), fetchTmpl)
 		if err != nil {
 			return nil, errors.Wrap(err, "error generating fetch module")
 		}
 
-		resp.File = append(resp.File, generatedFetch)
+		resp.File = append(resp.File, generatedFetch...)
 	}
 
 	return resp, nil
 }
 
-func (t *TypeScriptGRPCGatewayGenerator) generateFile(fileData *data.File, tmpl *template.Template) (*plugin.CodeGeneratorResponse_File, error) {
-	w := bytes.NewBufferString("")
+// lint reports the issues found in the files to generate as warnings, or as an error when LintStrict is on
+func (t *TypeScriptGRPCGatewayGenerator) lint(filesData map[string]*data.File) error {
+	issues := t.Registry.Lint(filesData)
+	if len(issues) == 0 {
+		return nil
+	}
 
-	if fileData.IsEmpty() {
-		w.Write([]byte(fmt.Sprintln("export default {}")))
-	} else {
-		err := tmpl.Execute(w, fileData)
-		if err != nil {
-			return nil, errors.Wrapf(err, "error generating ts file for %s", fileData.Name)
+	messages := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+
+	if t.LintStrict {
+		return errors.Errorf("%d lint issues found:\n%s", len(issues), strings.Join(messages, "\n"))
+	}
+
+	for _, message := range messages {
+		log.Warn(message)
+	}
+
+	return nil
+}
+
+// renderings returns the files to render for the typescript file name with the templates of the template set, it is the
+// typescript file itself, or the javascript file and the declaration file with target js, named .mjs and .d.mts with
+// import_extension .mjs. the root template of the set is used if the set doesn't have a dedicated template for the
+// javascript or the declaration file
+func (t *TypeScriptGRPCGatewayGenerator) renderings(tmpl *template.Template, tsFileName string) []rendering {
+	if t.Registry.Target != registry.TargetJS {
+		return []rendering{{template: tmpl.Name(), fileName: tsFileName}}
+	}
+
+	extensions := map[string]string{jsExtension: jsExtension, dtsExtension: dtsExtension}
+	if t.Registry.ImportExtension == mjsExtension {
+		extensions = map[string]string{jsExtension: mjsExtension, dtsExtension: dmtsExtension}
+	}
+
+	renderings := make([]rendering, 0, 2)
+	for _, extension := range []string{jsExtension, dtsExtension} {
+		name := tmpl.Name() + extension
+		if tmpl.Lookup(name) == nil {
+			name = tmpl.Name()
 		}
+
+		renderings = append(renderings, rendering{
+			template:    name,
+			fileName:    strings.TrimSuffix(tsFileName, ".ts") + extensions[extension],
+			declaration: extension == dtsExtension,
+		})
 	}
 
-	fileName := fileData.TSFileName
-	content := strings.TrimSpace(w.String())
+	return renderings
+}
 
-	return &plugin.CodeGeneratorResponse_File{
-		Name:           &fileName,
-		InsertionPoint: nil,
-		Content:        &content,
-	}, nil
+func (t *TypeScriptGRPCGatewayGenerator) generateFile(fileData *data.File, tmpl *template.Template) ([]*plugin.CodeGeneratorResponse_File, error) {
+	files := make([]*plugin.CodeGeneratorResponse_File, 0)
+	for _, rendering := range t.renderings(tmpl, fileData.TSFileName) {
+		w := bytes.NewBufferString("")
+
+		if fileData.IsEmpty() && rendering.declaration {
+			// the same as what tsc declares for export default {}
+			w.Write([]byte(fmt.Sprintln("declare const _default: {}")))
+			w.Write([]byte(fmt.Sprintln("export default _default")))
+		} else if fileData.IsEmpty() {
+			w.Write([]byte(fmt.Sprintln("export default {}")))
+		} else {
+			err := tmpl.Funcs(fileFuncs(t.Registry.ForFile(fileData.Name, fileData.Package), fileData)).ExecuteTemplate(w, rendering.template, fileData)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error generating %s for %s", rendering.fileName, fileData.Name)
+			}
+		}
+
+		fileName := rendering.fileName
+		content := strings.TrimSpace(w.String())
+
+		files = append(files, &plugin.CodeGeneratorResponse_File{
+			Name:           &fileName,
+			InsertionPoint: nil,
+			Content:        &content,
+		})
+	}
+
+	return files, nil
 }
 
-func (t *TypeScriptGRPCGatewayGenerator) generateFetchModule(tmpl *template.Template) (*plugin.CodeGeneratorResponse_File, error) {
-	w := bytes.NewBufferString("")
-	fileName := filepath.Join(t.Registry.FetchModuleDirectory, t.Registry.FetchModuleFilename)
-	err := tmpl.Execute(w, &data.File{EnableStylingCheck: t.EnableStylingCheck})
-	if err != nil {
-		return nil, errors.Wrapf(err, "error generating fetch module at %s", fileName)
+func (t *TypeScriptGRPCGatewayGenerator) generateIndexFile(indexFile *data.IndexFile, tmpl *template.Template) ([]*plugin.CodeGeneratorResponse_File, error) {
+	files := make([]*plugin.CodeGeneratorResponse_File, 0)
+	for _, rendering := range t.renderings(tmpl, indexFile.Name) {
+		w := bytes.NewBufferString("")
+		err := tmpl.ExecuteTemplate(w, rendering.template, indexFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error generating index file for package %s", indexFile.Package)
+		}
+
+		fileName := rendering.fileName
+		content := strings.TrimSpace(w.String())
+		files = append(files, &plugin.CodeGeneratorResponse_File{
+			Name:           &fileName,
+			InsertionPoint: nil,
+			Content:        &content,
+		})
 	}
 
-	content := strings.TrimSpace(w.String())
-	return &plugin.CodeGeneratorResponse_File{
-		Name:           &fileName,
-		InsertionPoint: nil,
-		Content:        &content,
-	}, nil
+	return files, nil
+}
+
+func (t *TypeScriptGRPCGatewayGenerator) generateFetchModule(fetchModule *data.FetchModule, tmpl *template.Template) ([]*plugin.CodeGeneratorResponse_File, error) {
+	files := make([]*plugin.CodeGeneratorResponse_File, 0)
+	fetchModule.EnableStylingCheck = t.EnableStylingCheck
+	for _, rendering := range t.renderings(tmpl, t.Registry.GetFetchModulePath()) {
+		w := bytes.NewBufferString("")
+		fileName := rendering.fileName
+		err := tmpl.ExecuteTemplate(w, rendering.template, fetchModule)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error generating fetch module at %s", fileName)
+		}
+
+		content := strings.TrimSpace(w.String())
+		files = append(files, &plugin.CodeGeneratorResponse_File{
+			Name:           &fileName,
+			InsertionPoint: nil,
+			Content:        &content,
+		})
+	}
+
+	return files, nil
 }
@@ -0,0 +1,623 @@
+package generator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	descriptorpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/registry"
+)
+
+// loadFixture reads the file descriptor set in the text format from testdata, in the same order as protoc passes
+// them to the plugin. the files imported from outside the set, such as the google api annotations, are taken from
+// the descriptors linked into the test binary. the names of the files in the set are returned along with them
+func loadFixture(t *testing.T, fixture string) (*descriptorpb.FileDescriptorSet, []string) {
+	content, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	if !assert.NoError(t, prototext.Unmarshal(content, set)) {
+		t.FailNow()
+	}
+
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(set.File))
+	for _, f := range set.File {
+		seen[f.GetName()] = true
+		names = append(names, f.GetName())
+	}
+
+	files := make([]*descriptorpb.FileDescriptorProto, 0, len(set.File))
+	var addLinked func(fd protoreflect.FileDescriptor)
+	addLinked = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			addLinked(imports.Get(i).FileDescriptor)
+		}
+		files = append(files, protodesc.ToFileDescriptorProto(fd))
+	}
+
+	for _, f := range set.File {
+		for _, dependency := range f.GetDependency() {
+			if seen[dependency] {
+				continue
+			}
+
+			fd, err := protoregistry.GlobalFiles.FindFileByPath(dependency)
+			if !assert.NoError(t, err, "%s imported by %s", dependency, f.GetName()) {
+				t.FailNow()
+			}
+			addLinked(fd)
+		}
+	}
+
+	return &descriptorpb.FileDescriptorSet{File: append(files, set.File...)}, names
+}
+
+// generateFixture generates the files of the fixture with the options and returns the contents by the file names,
+// the files to generate default to the files in the fixture
+func generateFixture(t *testing.T, fixture string, opts Options, filesToGenerate ...string) (map[string]string, error) {
+	fds, names := loadFixture(t, fixture)
+	if len(filesToGenerate) == 0 {
+		filesToGenerate = names
+	}
+
+	files, err := GenerateFiles(fds, filesToGenerate, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make(map[string]string, len(files))
+	for _, f := range files {
+		contents[f.Name] = f.Content
+	}
+
+	return contents, nil
+}
+
+func TestGenerateFilesUnboundMethods(t *testing.T) {
+	omitted := DefaultOptions()
+	omitted.OmitUnboundMethods = true
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected bool
+	}{
+		{name: "zero value", opts: Options{}, expected: true},
+		{name: "defaults", opts: DefaultOptions(), expected: true},
+		{name: "omitted", opts: omitted, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := generateFixture(t, "exclude.textpb", tt.opts)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			content := files["counter/counter.pb.ts"]
+			assert.Contains(t, content, "export type Counter = {")
+			assert.Equal(t, tt.expected, strings.Contains(content, "/counter.CounterService/Increment"), content)
+			// the service and the method excluded with the ts_exclude options are left out either way
+			assert.NotContains(t, content, "AdminService")
+			assert.NotContains(t, content, "counter:reset")
+		})
+	}
+}
+
+func TestGenerateFilesZeroValueMatchesDefaults(t *testing.T) {
+	zero, err := generateFixture(t, "exclude.textpb", Options{})
+	assert.NoError(t, err)
+	defaults, err := generateFixture(t, "exclude.textpb", DefaultOptions())
+	assert.NoError(t, err)
+
+	assert.Equal(t, defaults, zero)
+}
+
+func TestGenerateFilesFieldMaskPaths(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []*registry.Override
+		expected  string
+	}{
+		{
+			name: "json names",
+			expected: `export const BookFieldMaskPaths = {
+  "bookTitle": "bookTitle",
+  "mainAuthor": "mainAuthor",
+  "mainAuthor.displayName": "mainAuthor.displayName",
+  "isbn10": "isbn10",
+  "foo1Bar": "foo1bar",
+  "aB": "aB",
+  "coAuthors": "coAuthors",
+} as const`,
+		},
+		{
+			name:      "proto names",
+			overrides: []*registry.Override{{Package: "book", Params: map[string]string{registry.UseProtoNames: "true"}}},
+			expected: `export const BookFieldMaskPaths = {
+  "book_title": "bookTitle",
+  "main_author": "mainAuthor",
+  "main_author.display_name": "mainAuthor.displayName",
+  "isbn_10": "isbn10",
+  "foo_1bar": "foo1bar",
+  "a_B": "aB",
+  "co_authors": "coAuthors",
+} as const`,
+		},
+		{
+			name:      "proto names in the nested file",
+			overrides: []*registry.Override{{File: "book/author.proto", Params: map[string]string{registry.UseProtoNames: "true"}}},
+			expected: `export const BookFieldMaskPaths = {
+  "bookTitle": "bookTitle",
+  "mainAuthor": "mainAuthor",
+  "mainAuthor.display_name": "mainAuthor.displayName",
+  "isbn10": "isbn10",
+  "foo1Bar": "foo1bar",
+  "aB": "aB",
+  "coAuthors": "coAuthors",
+} as const`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.FieldMaskDepth = 2
+			opts.Overrides = tt.overrides
+
+			files, err := generateFixture(t, "field_mask.textpb", opts)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			assert.Contains(t, files["book/book.pb.ts"], tt.expected)
+			assert.Contains(t, files["book/book.pb.ts"], "export type BookFieldMaskPath = typeof BookFieldMaskPaths[keyof typeof BookFieldMaskPaths]")
+		})
+	}
+}
+
+func TestFieldMaskPathSegment(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "title", expected: "title"},
+		{name: "book_title", expected: "bookTitle"},
+		{name: "isbn_10", expected: "isbn10"},
+		{name: "foo__bar", expected: "fooBar"},
+		{name: "foo_1bar", expected: "foo1bar"},
+		{name: "a_B", expected: "aB"},
+		{name: "trailing_", expected: "trailing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, fieldMaskPathSegment(&data.Field{Name: tt.name}))
+		})
+	}
+}
+
+func TestGenerateFilesLongRunningOperations(t *testing.T) {
+	tests := []struct {
+		serviceStyle string
+		expected     []string
+	}{
+		{
+			serviceStyle: registry.ServiceStyleClass,
+			expected: []string{
+				"  static CreateBookOperation(req: CreateBookRequest, initReq?: fm.InitReq): Promise<fm.LongRunningOperation<Book, CreateBookMetadata>> {\n" +
+					"    return fm.fetchReq<CreateBookRequest, fm.Operation<Book, CreateBookMetadata>>(`/v1/${req[\"parent\"]}/books`, {...initReq, method: \"POST\", body: JSON.stringify(req[\"book\"], fm.replacer)}).then(op => fm.newLongRunningOperation(op, (name: string) => `/v1/${name}`, initReq))\n" +
+					"  }",
+				"  static PurgeBooksOperation(req: CreateBookRequest, initReq?: fm.InitReq): Promise<fm.LongRunningOperation<GoogleProtobufEmpty.Empty, unknown>> {",
+			},
+		},
+		{
+			serviceStyle: registry.ServiceStyleFunctions,
+			expected: []string{
+				"export function libraryCreateBookOperation(req: CreateBookRequest, initReq?: fm.InitReq): Promise<fm.LongRunningOperation<Book, CreateBookMetadata>> {",
+				"export function libraryPurgeBooksOperation(req: CreateBookRequest, initReq?: fm.InitReq): Promise<fm.LongRunningOperation<GoogleProtobufEmpty.Empty, unknown>> {",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serviceStyle, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.ServiceStyle = tt.serviceStyle
+			files, err := generateFixture(t, "long_running.textpb", opts)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			content := files["library/library.pb.ts"]
+			for _, expected := range tt.expected {
+				assert.Contains(t, content, expected)
+			}
+			// only the methods returning operations get the operation wrapper
+			assert.NotContains(t, content, "GetBookOperation")
+			assert.Contains(t, files["fetch.pb.ts"], "export function newLongRunningOperation<R, M>(")
+		})
+	}
+}
+
+func TestGenerateFilesNestedTypeNamespaces(t *testing.T) {
+	opts := DefaultOptions()
+	opts.NestedTypeNaming = registry.NestedTypeNamingNamespace
+	files, err := generateFixture(t, "nested.textpb", opts)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	content := files["nested/nested.pb.ts"]
+	assert.Contains(t, content, "export type FooBar = {\n  bar?: Foo.Bar\n}")
+	assert.Contains(t, content, "export namespace Foo {\n  export enum Level {")
+	assert.Contains(t, content, "  export type Bar = {\n    deep?: Foo.Bar.Deep\n  }\n}")
+	assert.Contains(t, content, "export namespace Foo.Bar {\n  export type Deep = {\n  }\n}")
+}
+
+func TestGenerateFilesReservedNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(opts *Options)
+		expected []string
+	}{
+		{
+			name:   "class",
+			change: func(opts *Options) {},
+			expected: []string{
+				"export enum Record_ {\n  \"constructor\" = \"constructor\",\n  \"default\" = \"default\",\n}",
+				"export type ObjectDate = {\n}",
+				"export type Object_ = {\n  \"delete\"?: string\n  \"class\"?: ObjectDate\n  payload?: Uint8Array\n}",
+				"export type Promise_ = {\n  kind?: Record_\n}",
+				"export class Error_ {\n  static Name(req: Object_, initReq?: fm.InitReq): Promise<Promise_> {",
+				"  static length_(req: Object_, initReq?: fm.InitReq): Promise<Promise_> {",
+			},
+		},
+		{
+			name: "namespaces and functions",
+			change: func(opts *Options) {
+				opts.NestedTypeNaming = registry.NestedTypeNamingNamespace
+				opts.ServiceStyle = registry.ServiceStyleFunctions
+			},
+			expected: []string{
+				"  \"class\"?: Object_.Date_\n",
+				"export namespace Object_ {\n  export type Date_ = {\n  }\n}",
+				"export function error_Name(req: Object_, initReq?: fm.InitReq): Promise<Promise_> {",
+				"export function error_length_(req: Object_, initReq?: fm.InitReq): Promise<Promise_> {",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			tt.change(&opts)
+			files, err := generateFixture(t, "reserved.textpb", opts)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			for _, expected := range tt.expected {
+				assert.Contains(t, files["reserved/reserved.pb.ts"], expected)
+			}
+		})
+	}
+}
+
+func TestGenerateFilesTypeOnlyImports(t *testing.T) {
+	files, err := generateFixture(t, "imports.textpb", DefaultOptions())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	content := files["shop/order.pb.ts"]
+	// only the message of catalog/product.proto is referenced, the module is erased from the compiled code
+	assert.Contains(t, content, "import type * as CatalogProduct from \"../catalog/product.pb\"\n")
+	// the enum of shop/common/item.proto is a value, the module is imported at runtime
+	assert.Contains(t, content, "import * as ShopCommonItem from \"./common/item.pb\"\n")
+}
+
+// TestGenerateFilesHermeticImports generates the files from a directory holding a copy of an imported proto under
+// ts_import_roots and from an empty one, the hermetic imports are the same in both while the filesystem ones are not
+func TestGenerateFilesHermeticImports(t *testing.T) {
+	fds, names := loadFixture(t, "imports.textpb")
+
+	root, err := ioutil.TempDir("", "hermetic")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(root)
+
+	withProtos := filepath.Join(root, "with_protos")
+	empty := filepath.Join(root, "empty")
+	for _, dir := range []string{filepath.Join(withProtos, "protos", "catalog"), filepath.Join(withProtos, "out"), empty} {
+		if !assert.NoError(t, os.MkdirAll(dir, 0700)) {
+			t.FailNow()
+		}
+	}
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(withProtos, "protos", "catalog", "product.proto"), nil, 0600)) {
+		t.FailNow()
+	}
+
+	cwd, err := os.Getwd()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Chdir(cwd)
+
+	generate := func(dir, importResolution string) map[string]string {
+		if !assert.NoError(t, os.Chdir(dir)) {
+			t.FailNow()
+		}
+
+		opts := DefaultOptions()
+		opts.ImportResolution = importResolution
+		opts.TSImportRoots = []string{"../protos"}
+		files, err := GenerateFiles(fds, names, opts)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		contents := make(map[string]string, len(files))
+		for _, f := range files {
+			contents[f.Name] = f.Content
+		}
+
+		return contents
+	}
+
+	hermetic := generate(filepath.Join(withProtos, "out"), registry.ImportResolutionHermetic)
+	assert.Equal(t, hermetic, generate(empty, registry.ImportResolutionHermetic))
+	assert.Contains(t, hermetic["shop/order.pb.ts"], "import type * as CatalogProduct from \"../catalog/product.pb\"\n")
+
+	filesystem := generate(filepath.Join(withProtos, "out"), registry.ImportResolutionFilesystem)
+	assert.Contains(t, filesystem["shop/order.pb.ts"], "import type * as CatalogProduct from \"../../protos/catalog/product.pb\"\n")
+}
+
+func TestGenerateFilesImportExtension(t *testing.T) {
+	tests := []struct {
+		name            string
+		target          string
+		importExtension string
+		expectedFiles   []string
+		expectedImport  string
+		expectedErr     string
+	}{
+		{name: "none", expectedFiles: []string{"shop/order.pb.ts"}, expectedImport: `from "./common/item.pb"`},
+		{name: "js", importExtension: ".js", expectedFiles: []string{"shop/order.pb.ts"}, expectedImport: `from "./common/item.pb.js"`},
+		{name: "ts", importExtension: ".ts", expectedFiles: []string{"shop/order.pb.ts"}, expectedImport: `from "./common/item.pb.ts"`},
+		{name: "mjs with target ts", importExtension: ".mjs", expectedErr: "import_extension .mjs needs target js, which generates the .mjs files"},
+		{name: "target js", target: registry.TargetJS, expectedFiles: []string{"shop/order.pb.js", "shop/order.pb.d.ts"}, expectedImport: `from "./common/item.pb.js"`},
+		{
+			name:            "mjs with target js",
+			target:          registry.TargetJS,
+			importExtension: ".mjs",
+			expectedFiles:   []string{"shop/order.pb.mjs", "shop/order.pb.d.mts"},
+			expectedImport:  `from "./common/item.pb.mjs"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Target = tt.target
+			opts.ImportExtension = tt.importExtension
+			files, err := generateFixture(t, "imports.textpb", opts)
+			if tt.expectedErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.expectedErr)
+				}
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			for _, name := range tt.expectedFiles {
+				assert.Contains(t, files, name)
+			}
+			assert.Len(t, files, 3*len(tt.expectedFiles))
+			// the javascript file doesn't import the modules only referenced in types, the declaration file does
+			assert.Contains(t, files[tt.expectedFiles[len(tt.expectedFiles)-1]], tt.expectedImport)
+		})
+	}
+}
+
+func TestGenerateFilesFetchModulePackage(t *testing.T) {
+	versionCheck := "type _FetchModuleAPIVersion<V extends " + registry.FetchModuleAPIVersion + " = typeof fm.fetchModuleAPIVersion> = V\n"
+
+	tests := []struct {
+		name        string
+		target      string
+		version     string
+		expected    map[string]string
+		expectedErr string
+	}{
+		{name: "missing version", expectedErr: "fetch_module_package_version is required with fetch_module_package"},
+		{name: "incompatible version", version: "0", expectedErr: "fetch module API version 0 of @org/fetch is incompatible with the generator"},
+		{name: "typescript", version: registry.FetchModuleAPIVersion, expected: map[string]string{"counter/counter.pb.ts": versionCheck}},
+		{
+			name:     "javascript",
+			target:   registry.TargetJS,
+			version:  registry.FetchModuleAPIVersion,
+			expected: map[string]string{"counter/counter.pb.js": "", "counter/counter.pb.d.ts": versionCheck},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Target = tt.target
+			opts.FetchModulePackage = "@org/fetch"
+			opts.FetchModulePackageVersion = tt.version
+			files, err := generateFixture(t, "exclude.textpb", opts)
+			if tt.expectedErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.expectedErr)
+				}
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			// the fetch module is imported from the package instead of being generated
+			assert.Len(t, files, len(tt.expected))
+			for name, expected := range tt.expected {
+				content := files[name]
+				assert.Contains(t, content, `import * as fm from "@org/fetch"`)
+				// the version is checked without being exported
+				assert.NotContains(t, content, "export const fetchModuleAPIVersion")
+				assert.NotContains(t, content, "export declare const fetchModuleAPIVersion")
+				if expected != "" {
+					assert.Contains(t, content, expected)
+				} else {
+					assert.NotContains(t, content, "fetchModuleAPIVersion")
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateFilesFetchModuleFeatures(t *testing.T) {
+	tests := []struct {
+		name            string
+		fixture         string
+		filesToGenerate []string
+		expectedValues  []string
+		expectedTypes   []string
+	}{
+		{
+			name:           "query string",
+			fixture:        "exclude.textpb",
+			expectedValues: []string{"fetchModuleAPIVersion", "fetchReq", "renderURLSearchParams", "replacer"},
+			expectedTypes:  []string{"InitReq"},
+		},
+		{
+			name:            "server streaming and bytes fields",
+			fixture:         "fetch.textpb",
+			filesToGenerate: []string{"media/media.proto"},
+			expectedValues:  []string{"b64Decode", "b64Encode", "fetchModuleAPIVersion", "fetchReq", "fetchStreamingRequest", "replacer"},
+			expectedTypes:   []string{"InitReq", "NotifyStreamEntityArrival"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.FetchModuleFeatures = registry.FetchModuleFeaturesUsed
+			files, err := generateFixture(t, tt.fixture, opts, tt.filesToGenerate...)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			values, types := exports(files["fetch.pb.ts"])
+			assert.Equal(t, tt.expectedValues, values)
+			assert.Equal(t, tt.expectedTypes, types)
+		})
+	}
+}
+
+func TestGenerateFilesServiceStyles(t *testing.T) {
+	tests := []struct {
+		serviceStyle string
+		expected     []string
+		expectedErr  string
+	}{
+		{
+			serviceStyle: registry.ServiceStyleClass,
+			expected: []string{
+				"export class Library {\n  static GetBook(req: GetBookRequest, initReq?: fm.InitReq): Promise<GetBookRequest> {",
+				"  static GetBookWithName(name: string, initReq?: fm.InitReq): Promise<GetBookRequest> {\n    return Library.GetBook({name: name}, initReq)\n  }",
+			},
+		},
+		{
+			serviceStyle: registry.ServiceStyleFunctions,
+			expectedErr:  "1 lint issues found:\nlibrary/library.proto:22:3: library.LibraryGetBook.WithName: TypeScript identifier libraryGetBookWithName collides with the function of method signature \"name\" of library.Library.GetBook",
+		},
+		{
+			serviceStyle: registry.ServiceStyleFunctionsAndClass,
+			expectedErr:  "1 lint issues found:\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serviceStyle, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.ServiceStyle = tt.serviceStyle
+			opts.LintStrict = true
+			files, err := generateFixture(t, "functions.textpb", opts)
+			if tt.expectedErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.expectedErr)
+				}
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			for _, expected := range tt.expected {
+				assert.Contains(t, files["library/library.pb.ts"], expected)
+			}
+		})
+	}
+}
+
+func TestGenerateFilesClientFiles(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ClientFileSuffix = ".client.ts"
+	opts.GenerateIndexFiles = true
+	files, err := generateFixture(t, "exclude.textpb", opts)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	types := files["counter/counter.pb.ts"]
+	assert.Contains(t, types, "export type Counter = {")
+	assert.NotContains(t, types, "import")
+	assert.NotContains(t, types, "CounterService")
+
+	client := files["counter/counter.client.ts"]
+	assert.Contains(t, client, "import * as fm from \"../fetch.pb\"\nimport type * as CounterCounter from \"./counter.pb\"\n")
+	assert.Contains(t, client, "static Get(req: CounterCounter.Counter, initReq?: fm.InitReq): Promise<CounterCounter.Counter> {")
+	assert.NotContains(t, client, "export type Counter")
+
+	assert.Contains(t, files["counter/index.ts"],
+		"export * as CounterCounter from \"./counter.pb\"\nexport * as CounterCounterClient from \"./counter.client\"")
+}
+
+func TestGenerateFilesLintStrict(t *testing.T) {
+	opts := DefaultOptions()
+	files, err := generateFixture(t, "lint.textpb", opts)
+	assert.NoError(t, err)
+	assert.Contains(t, files, "lint/lint.pb.ts")
+
+	opts.LintStrict = true
+	_, err = generateFixture(t, "lint.textpb", opts)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "6 lint issues found:\nlint/lint.proto:6:1: lint.Book: TypeScript identifier BaseBook collides with lint.BaseBook\n")
+	}
+}
@@ -0,0 +1,782 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/registry"
+)
+
+// jsTmpl renders the javascript file of the proto file with target js, only the enums, the field mask paths and
+// the services exist at runtime, the types are left to the declaration file
+const jsTmpl = `
+{{define "jsDependencies"}}
+{{range .}}{{if .Runtime}}import * as {{.ModuleIdentifier}} from "{{.SourceFile}}"
+{{end}}{{end}}{{end}}
+
+{{define "jsEnums"}}
+{{range .}}export const {{.Name}} = {
+{{- range .Values}}
+  {{propertyName .}}: "{{.}}",
+{{- end}}
+}
+
+{{end}}{{end}}
+
+{{define "jsMessages"}}{{range .}}{{if fieldMaskPaths .}}export const {{.Name}}FieldMaskPaths = {
+{{- range fieldMaskPaths .}}
+  "{{.Key}}": "{{.Path}}",
+{{- end}}
+}
+
+{{end}}{{end}}{{end}}
+
+{{define "jsNamespace"}}{
+{{- range .Enums}}
+  {{.Name}}: {
+{{- range .Values}}
+    {{propertyName .}}: "{{.}}",
+{{- end}}
+  },
+{{- end}}
+{{- range .Messages}}{{if fieldMaskPaths .}}
+  {{.Name}}FieldMaskPaths: {
+{{- range fieldMaskPaths .}}
+    "{{.Key}}": "{{.Path}}",
+{{- end}}
+  },
+{{- end}}{{end}}
+{{- range .Children}}
+  {{.Name}}: {{include "jsNamespace" . | indentBlock 2 | trim}},
+{{- end}}
+}{{end}}
+
+{{define "jsServices"}}
+{{- if renderServiceFunctions}}{{include "jsFunctions" . | trimPrefix "\n"}}{{end}}
+{{- if and renderServiceClasses renderServiceFunctions}}{{include "composedClasses" .}}
+{{- else if renderServiceClasses}}{{include "jsClasses" .}}{{end}}
+{{- end}}
+
+{{define "jsFunctions"}}{{range $service := .}}
+{{- range $method := .Methods}}
+{{- if .ServerStreaming}}
+export function {{$service.FunctionName .Name}}(req, entityNotifier, initReq) {
+  return fm.fetchStreamingRequest(` + "`{{renderURL .}}`" + `, entityNotifier, {...initReq, {{buildInitReq .}}})
+}
+{{range .Signatures}}
+export function {{$service.FunctionName .Name}}({{range .Params}}{{.Name}}, {{end}}entityNotifier, initReq) {
+  return {{$service.FunctionName $method.Name}}({{signatureRequest .}}, entityNotifier, initReq)
+}
+{{end}}
+{{- else}}
+export function {{$service.FunctionName .Name}}(req, initReq) {
+  return fm.fetchReq(` + "`{{renderURL .}}`" + `, {...initReq, {{buildInitReq .}}})
+}
+{{if .LongRunning}}
+export function {{$service.FunctionName (print .Name "Operation")}}(req, initReq) {
+  return fm.fetchReq(` + "`{{renderURL .}}`" + `, {...initReq, {{buildInitReq .}}}).then(op => fm.newLongRunningOperation(op, (name) => ` + "`{{renderOperationURL .LongRunning}}`" + `, initReq))
+}
+{{end}}
+{{- range .Signatures}}
+export function {{$service.FunctionName .Name}}({{range .Params}}{{.Name}}, {{end}}initReq) {
+  return {{$service.FunctionName $method.Name}}({{signatureRequest .}}, initReq)
+}
+{{end}}
+{{- end}}
+{{- end}}
+{{- end}}{{end}}
+
+{{define "jsClasses"}}{{range $service := .}}export class {{.Name}} {
+{{- range $method := .Methods}}
+{{- if .ServerStreaming}}
+  static {{.Name}}(req, entityNotifier, initReq) {
+    return fm.fetchStreamingRequest(` + "`{{renderURL .}}`" + `, entityNotifier, {...initReq, {{buildInitReq .}}})
+  }
+{{- range .Signatures}}
+  static {{.Name}}({{range .Params}}{{.Name}}, {{end}}entityNotifier, initReq) {
+    return {{$service.Name}}.{{$method.Name}}({{signatureRequest .}}, entityNotifier, initReq)
+  }
+{{- end}}
+{{- else}}
+  static {{.Name}}(req, initReq) {
+    return fm.fetchReq(` + "`{{renderURL .}}`" + `, {...initReq, {{buildInitReq .}}})
+  }
+{{- if .LongRunning}}
+  static {{.Name}}Operation(req, initReq) {
+    return fm.fetchReq(` + "`{{renderURL .}}`" + `, {...initReq, {{buildInitReq .}}}).then(op => fm.newLongRunningOperation(op, (name) => ` + "`{{renderOperationURL .LongRunning}}`" + `, initReq))
+  }
+{{- end}}
+{{- range .Signatures}}
+  static {{.Name}}({{range .Params}}{{.Name}}, {{end}}initReq) {
+    return {{$service.Name}}.{{$method.Name}}({{signatureRequest .}}, initReq)
+  }
+{{- end}}
+{{- end}}
+{{- end}}
+}
+{{end}}{{end}}
+
+{{- if not .EnableStylingCheck}}
+/* eslint-disable */
+// @ts-nocheck
+{{- end}}
+/*
+* This file is a generated Javascript file for GRPC Gateway, DO NOT MODIFY
+*/
+{{if .Dependencies}}{{- include "jsDependencies" .StableDependencies -}}{{end}}
+{{- if .RootEnums}}{{include "jsEnums" .RootEnums}}{{end}}
+{{- if .RootMessages}}{{include "jsMessages" .RootMessages}}{{end}}
+{{- range jsNamespaces .}}export const {{.Name}} = {{include "jsNamespace" .}}
+
+{{end}}
+{{- if .Services}}{{include "jsServices" .Services}}{{end}}
+`
+
+// dtsTmpl renders the declaration file of the proto file with target js, which declares the same types as the
+// typescript file does, and the signatures of the values defined in the javascript file
+const dtsTmpl = `
+{{define "dtsMessages"}}{{range .}}{{include "messageType" .}}
+{{- if fieldMaskPaths .}}
+export const {{.Name}}FieldMaskPaths: {
+{{- range fieldMaskPaths .}}
+  readonly "{{.Key}}": "{{.Path}}"
+{{- end}}
+}
+
+export type {{.Name}}FieldMaskPath = typeof {{.Name}}FieldMaskPaths[keyof typeof {{.Name}}FieldMaskPaths]
+{{end}}
+{{end}}{{end}}
+
+{{define "dtsNamespace"}}export namespace {{.Name}} {
+{{- if .Enums}}
+{{include "enums" .Enums | trim | indentBlock 2}}
+{{- end}}
+{{- if .Messages}}
+{{if .Enums}}
+{{end}}{{include "dtsMessages" .Messages | trim | indentBlock 2}}
+{{- end}}
+}
+
+{{end}}
+
+{{define "dtsServices"}}
+{{- if renderServiceFunctions}}{{include "dtsFunctions" . | trimPrefix "\n"}}{{end}}
+{{- if renderServiceClasses}}{{if renderServiceFunctions}}
+{{end}}{{include "dtsClasses" .}}{{end}}
+{{- end}}
+
+{{define "dtsFunctions"}}{{range $service := .}}
+{{- range $method := .Methods}}
+{{- if .ServerStreaming}}
+export declare function {{$service.FunctionName .Name}}(req: {{tsType .Input}}, entityNotifier?: fm.NotifyStreamEntityArrival<{{tsType .Output}}>, initReq?: fm.InitReq): Promise<void>
+{{- range .Signatures}}
+export declare function {{$service.FunctionName .Name}}({{range .Params}}{{.Name}}: {{tsType .Type}}, {{end}}entityNotifier?: fm.NotifyStreamEntityArrival<{{tsType $method.Output}}>, initReq?: fm.InitReq): Promise<void>
+{{- end}}
+{{- else}}
+export declare function {{$service.FunctionName .Name}}(req: {{tsType .Input}}, initReq?: fm.InitReq): Promise<{{tsType .Output}}>
+{{- if .LongRunning}}
+export declare function {{$service.FunctionName (print .Name "Operation")}}(req: {{tsType .Input}}, initReq?: fm.InitReq): Promise<fm.LongRunningOperation<{{operationType .LongRunning.ResponseType}}, {{operationType .LongRunning.MetadataType}}>>
+{{- end}}
+{{- range .Signatures}}
+export declare function {{$service.FunctionName .Name}}({{range .Params}}{{.Name}}: {{tsType .Type}}, {{end}}initReq?: fm.InitReq): Promise<{{tsType $method.Output}}>
+{{- end}}
+{{- end}}
+{{- end}}
+{{end}}{{end}}
+
+{{define "dtsClasses"}}{{range $service := .}}export declare class {{.Name}} {
+{{- range $method := .Methods}}
+{{- if .ServerStreaming}}
+  static {{.Name}}(req: {{tsType .Input}}, entityNotifier?: fm.NotifyStreamEntityArrival<{{tsType .Output}}>, initReq?: fm.InitReq): Promise<void>
+{{- range .Signatures}}
+  static {{.Name}}({{range .Params}}{{.Name}}: {{tsType .Type}}, {{end}}entityNotifier?: fm.NotifyStreamEntityArrival<{{tsType $method.Output}}>, initReq?: fm.InitReq): Promise<void>
+{{- end}}
+{{- else}}
+  static {{.Name}}(req: {{tsType .Input}}, initReq?: fm.InitReq): Promise<{{tsType .Output}}>
+{{- if .LongRunning}}
+  static {{.Name}}Operation(req: {{tsType .Input}}, initReq?: fm.InitReq): Promise<fm.LongRunningOperation<{{operationType .LongRunning.ResponseType}}, {{operationType .LongRunning.MetadataType}}>>
+{{- end}}
+{{- range .Signatures}}
+  static {{.Name}}({{range .Params}}{{.Name}}: {{tsType .Type}}, {{end}}initReq?: fm.InitReq): Promise<{{tsType $method.Output}}>
+{{- end}}
+{{- end}}
+{{- end}}
+}
+{{end}}{{end}}
+
+{{- if not .EnableStylingCheck}}
+/* eslint-disable */
+// @ts-nocheck
+{{- end}}
+/*
+* This file is a generated Typescript declaration file for GRPC Gateway, DO NOT MODIFY
+*/
+{{if .Dependencies}}{{- include "dependencies" .StableDependencies -}}{{end}}
+{{- if .FetchModuleAPIVersion}}{{include "fetchModuleAPIVersion" .FetchModuleAPIVersion}}{{end}}
+{{- if .NeedsOneOfSupport}}
+type Absent<T, K extends keyof T> = { [k in Exclude<keyof T, K>]?: undefined };
+type OneOf<T> =
+  | { [k in keyof T]?: undefined }
+  | (
+    keyof T extends infer K ?
+      (K extends string & keyof T ? { [k in K]: T[K] } & Absent<T, K>
+        : never)
+    : never);
+{{end}}
+{{- if .RootEnums}}{{include "enums" .RootEnums}}{{end}}
+{{- if .RootMessages}}{{include "dtsMessages" .RootMessages}}{{end}}
+{{- range .Namespaces}}{{include "dtsNamespace" .}}{{end}}
+{{- if .Services}}{{include "dtsServices" .Services}}{{end}}
+`
+
+// fetchJSTmpl is the fetch module in javascript, which needs to be kept in line with fetchTmpl
+const fetchJSTmpl = `
+{{- if not .EnableStylingCheck}}
+/* eslint-disable */
+// @ts-nocheck
+{{- end}}
+/*
+* This file is a generated Javascript file for GRPC Gateway, DO NOT MODIFY
+*/
+
+// fetchModuleAPIVersion is the version of the API of the fetch module, which the generated code is compatible with
+export const fetchModuleAPIVersion = ` + registry.FetchModuleAPIVersion + `
+
+{{if .NeedsBase64}}/**
+ * base64 encoder and decoder
+ * Copied and adapted from https://github.com/protobufjs/protobuf.js/blob/master/lib/base64/index.js
+ */
+// Base64 encoding table
+const b64 = new Array(64);
+
+// Base64 decoding table
+const s64 = new Array(123);
+
+// 65..90, 97..122, 48..57, 43, 47
+for (let i = 0; i < 64;)
+    s64[b64[i] = i < 26 ? i + 65 : i < 52 ? i + 71 : i < 62 ? i - 4 : i - 59 | 43] = i++;
+
+export function b64Encode(buffer, start, end) {
+	let parts = null;
+  const chunk = [];
+  let i = 0, // output index
+    j = 0, // goto index
+    t;     // temporary
+  while (start < end) {
+    const b = buffer[start++];
+    switch (j) {
+      case 0:
+        chunk[i++] = b64[b >> 2];
+        t = (b & 3) << 4;
+        j = 1;
+        break;
+      case 1:
+        chunk[i++] = b64[t | b >> 4];
+        t = (b & 15) << 2;
+        j = 2;
+        break;
+      case 2:
+        chunk[i++] = b64[t | b >> 6];
+        chunk[i++] = b64[b & 63];
+        j = 0;
+        break;
+    }
+    if (i > 8191) {
+      (parts || (parts = [])).push(String.fromCharCode.apply(String, chunk));
+      i = 0;
+    }
+  }
+  if (j) {
+    chunk[i++] = b64[t];
+    chunk[i++] = 61;
+    if (j === 1)
+      chunk[i++] = 61;
+  }
+  if (parts) {
+    if (i)
+      parts.push(String.fromCharCode.apply(String, chunk.slice(0, i)));
+    return parts.join("");
+  }
+  return String.fromCharCode.apply(String, chunk.slice(0, i));
+}
+
+const invalidEncoding = "invalid encoding";
+
+export function b64Decode(s) {
+	const buffer = [];
+	let offset = 0;
+  let j = 0, // goto index
+      t;     // temporary
+  for (let i = 0; i < s.length;) {
+    let c = s.charCodeAt(i++);
+    if (c === 61 && j > 1)
+        break;
+    if ((c = s64[c]) === undefined)
+        throw Error(invalidEncoding);
+    switch (j) {
+      case 0:
+        t = c;
+        j = 1;
+        break;
+      case 1:
+        buffer[offset++] = t << 2 | (c & 48) >> 4;
+        t = c;
+        j = 2;
+        break;
+      case 2:
+        buffer[offset++] = (t & 15) << 4 | (c & 60) >> 2;
+        t = c;
+        j = 3;
+        break;
+      case 3:
+        buffer[offset++] = (t & 3) << 6 | c;
+        j = 0;
+        break;
+    }
+  }
+  if (j === 1)
+      throw Error(invalidEncoding);
+  return new Uint8Array(buffer);
+}
+
+{{end}}export function replacer(key, value) {
+{{- if .NeedsBase64}}
+  if(value && value.constructor === Uint8Array) {
+    return b64Encode(value, 0, value.length);
+  }
+{{end}}
+  return value;
+}
+
+export function fetchReq(path, init) {
+  const {pathPrefix, ...req} = init || {}
+
+  const url = pathPrefix ? ` + "`${pathPrefix}${path}`" + ` : path
+
+  return fetch(url, req).then(r => r.json().then((body) => {
+    if (!r.ok) { throw body; }
+    return body;
+  }))
+}
+
+{{if .NeedsLongRunningOperation}}export function newLongRunningOperation(operation, getOperationPath, init) {
+  return {
+    operation,
+    wait: (opts) => pollOperation(operation, getOperationPath, init, opts),
+  }
+}
+
+/**
+ * pollOperation calls GetOperation through the gateway with exponential backoff until the operation is done,
+ * it resolves to the operation response and rejects with the operation error if the operation has failed.
+ **/
+export async function pollOperation(operation, getOperationPath, init, opts) {
+  const {initialDelay = 500, maxDelay = 10000, multiplier = 1.5, timeout} = opts || {}
+  const deadline = timeout ? Date.now() + timeout : undefined
+  let delay = initialDelay
+  let op = operation
+  while (!op.done) {
+    if (deadline !== undefined && Date.now() + delay > deadline) {
+      throw new Error(` + "`timed out waiting for operation ${op.name}`" + `)
+    }
+    await new Promise(resolve => setTimeout(resolve, delay))
+    delay = Math.min(delay * multiplier, maxDelay)
+    op = await fetchReq(getOperationPath(op.name || ""), {...init, method: "GET", body: undefined})
+  }
+
+  if (op.error) {
+    throw op.error
+  }
+
+  return op.response
+}
+
+{{end}}{{if .NeedsServerStreaming}}/**
+ * fetchStreamingRequest is able to handle grpc-gateway server side streaming call
+ * it takes NotifyStreamEntityArrival that lets users respond to entity arrival during the call
+ * all entities will be returned as an array after the call finishes.
+ **/
+export async function fetchStreamingRequest(path, callback, init) {
+  const {pathPrefix, ...req} = init || {}
+  const url = pathPrefix ?` + "`${pathPrefix}${path}`" + ` : path
+  const result = await fetch(url, req)
+  // needs to use the .ok to check the status of HTTP status code
+  // http other than 200 will not throw an error, instead the .ok will become false.
+  // see https://developer.mozilla.org/en-US/docs/Web/API/Fetch_API/Using_Fetch#
+  if (!result.ok) {
+    const resp = await result.json()
+    const errMsg = resp.error && resp.error.message ? resp.error.message : ""
+    throw new Error(errMsg)
+  }
+
+  if (!result.body) {
+    throw new Error("response doesnt have a body")
+  }
+
+  await result.body
+    .pipeThrough(new TextDecoderStream())
+    .pipeThrough(getNewLineDelimitedJSONDecodingStream())
+    .pipeTo(getNotifyEntityArrivalSink((e) => {
+      if (callback) {
+        callback(e)
+      }
+    }))
+
+  // wait for the streaming to finish and return the success respond
+  return
+}
+
+/**
+ * getNewLineDelimitedJSONDecodingStream returns a TransformStream that's able to handle new line delimited json stream content into parsed entities
+ */
+function getNewLineDelimitedJSONDecodingStream() {
+  return new TransformStream({
+    start(controller) {
+      controller.buf = ''
+      controller.pos = 0
+    },
+
+    transform(chunk, controller) {
+      if (controller.buf === undefined) {
+        controller.buf = ''
+      }
+      if (controller.pos === undefined) {
+        controller.pos = 0
+      }
+      controller.buf += chunk
+      while (controller.pos < controller.buf.length) {
+        if (controller.buf[controller.pos] === '\n') {
+          const line = controller.buf.substring(0, controller.pos)
+          const response = JSON.parse(line)
+          controller.enqueue(response.result)
+          controller.buf = controller.buf.substring(controller.pos + 1)
+          controller.pos = 0
+        } else {
+          ++controller.pos
+        }
+      }
+    }
+  })
+
+}
+
+/**
+ * getNotifyEntityArrivalSink takes the NotifyStreamEntityArrival callback and return
+ * a sink that will call the callback on entity arrival
+ * @param notifyCallback
+ */
+function getNotifyEntityArrivalSink(notifyCallback) {
+  return new WritableStream({
+    write(entity) {
+      notifyCallback(entity)
+    }
+  })
+}
+
+{{end}}{{if .NeedsFieldMask}}/**
+ * fieldMaskFromDiff computes the field mask paths for the fields set in updated that differ from original.
+ * paths maps the field names as they are rendered, joined with dots, to the field mask paths, e.g. BookFieldMaskPaths.
+ * Nested messages are compared field by field when they have nested paths,
+ * everything else is compared as a whole and reported with its own path.
+ **/
+export function fieldMaskFromDiff(original, updated, paths, prefix = "") {
+  const originalObject = original || {}
+  const updatedObject = updated || {}
+
+  return Object.keys(updatedObject).reduce((acc, key) => {
+    const keyPath = prefix ? [prefix, key].join(".") : key
+    if (!Object.prototype.hasOwnProperty.call(paths, keyPath)) {
+      return acc
+    }
+
+    const originalValue = originalObject[key]
+    const updatedValue = updatedObject[key]
+    const hasNestedPaths = Object.keys(paths).some(k => k.startsWith(keyPath + "."))
+
+    if (hasNestedPaths && isPlainObject(originalValue) && isPlainObject(updatedValue)) {
+      return [...acc, ...fieldMaskFromDiff(originalValue, updatedValue, paths, keyPath)]
+    }
+
+    if (!isEqual(originalValue, updatedValue)) {
+      return [...acc, paths[keyPath]]
+    }
+
+    return acc
+  }, [])
+}
+
+/**
+ * Checks if given values are deeply equal, plain objects are compared key by key
+ * and arrays including Uint8Array are compared element by element
+ * @param  {unknown} a
+ * @param  {unknown} b
+ * @return {boolean}
+ */
+function isEqual(a, b) {
+  if (a === b) {
+    return true
+  }
+
+  if ((Array.isArray(a) && Array.isArray(b)) || (a instanceof Uint8Array && b instanceof Uint8Array)) {
+    return a.length === b.length && Array.prototype.every.call(a, (v, i) => isEqual(v, b[i]))
+  }
+
+  if (isPlainObject(a) && isPlainObject(b)) {
+    const keys = new Set([...Object.keys(a), ...Object.keys(b)])
+    return Array.from(keys).every(k => isEqual(a[k], b[k]))
+  }
+
+  return false
+}
+
+{{end}}{{if or .NeedsFieldMask .NeedsURLSearchParams}}/**
+ * Checks if given value is a plain object
+ * Logic copied and adapted from below source:
+ * https://github.com/char0n/ramda-adjunct/blob/master/src/isPlainObj.js
+ * @param  {unknown} value
+ * @return {boolean}
+ */
+function isPlainObject(value) {
+  const isObject =
+    Object.prototype.toString.call(value).slice(8, -1) === "Object";
+  const isObjLike = value !== null && isObject;
+
+  if (!isObjLike || !isObject) {
+    return false;
+  }
+
+  const proto = Object.getPrototypeOf(value);
+
+  const hasObjectConstructor =
+    typeof proto === "object" &&
+    proto.constructor === Object.prototype.constructor;
+
+  return hasObjectConstructor;
+}
+
+{{end}}{{if .NeedsURLSearchParams}}/**
+ * Checks if given value is of a primitive type
+ * @param  {unknown} value
+ * @return {boolean}
+ */
+function isPrimitive(value) {
+  return ["string", "number", "boolean"].some(t => typeof value === t);
+}
+
+/**
+ * Checks if given primitive is zero-value
+ * @param  {Primitive} value
+ * @return {boolean}
+ */
+function isZeroValuePrimitive(value) {
+  return value === false || value === 0 || value === "";
+}
+
+/**
+ * Flattens a deeply nested request payload and returns an object
+ * with only primitive values and non-empty array of primitive values
+ * as per https://github.com/googleapis/googleapis/blob/master/google/api/http.proto
+ * @param  {RequestPayload} requestPayload
+ * @param  {String} path
+ * @return {FlattenedRequestPayload>}
+ */
+function flattenRequestPayload(
+  requestPayload,
+  path = ""
+) {
+  return Object.keys(requestPayload).reduce(
+    (acc, key) => {
+      const value = requestPayload[key];
+      const newPath = path ? [path, key].join(".") : key;
+
+      const isNonEmptyPrimitiveArray =
+        Array.isArray(value) &&
+        value.every(v => isPrimitive(v)) &&
+        value.length > 0;
+
+      const isNonZeroValuePrimitive =
+        isPrimitive(value) && !isZeroValuePrimitive(value);
+
+      let objectToMerge = {};
+
+      if (isPlainObject(value)) {
+        objectToMerge = flattenRequestPayload(value, newPath);
+      } else if (isNonZeroValuePrimitive || isNonEmptyPrimitiveArray) {
+        objectToMerge = { [newPath]: value };
+      }
+
+      return { ...acc, ...objectToMerge };
+    },
+    {}
+  );
+}
+
+/**
+ * Renders a deeply nested request payload into a string of URL search
+ * parameters by first flattening the request payload and then removing keys
+ * which are already present in the URL path.
+ * @param  {RequestPayload} requestPayload
+ * @param  {string[]} urlPathParams
+ * @return {string}
+ */
+export function renderURLSearchParams(
+  requestPayload,
+  urlPathParams = []
+) {
+  const flattenedRequestPayload = flattenRequestPayload(requestPayload);
+
+  const urlSearchParams = Object.keys(flattenedRequestPayload).reduce(
+    (acc, key) => {
+      // key should not be present in the url path as a parameter
+      const value = flattenedRequestPayload[key];
+      if (urlPathParams.find(f => f === key)) {
+        return acc;
+      }
+      return Array.isArray(value)
+        ? [...acc, ...value.map(m => [key, m.toString()])]
+        : (acc = [...acc, [key, value.toString()]]);
+    },
+    []
+  );
+
+  return new URLSearchParams(urlSearchParams).toString();
+}
+{{end}}`
+
+// fetchDTSTmpl is the declaration file of the fetch module in javascript, which needs to be kept in line with fetchTmpl
+const fetchDTSTmpl = `
+{{- if not .EnableStylingCheck}}
+/* eslint-disable */
+// @ts-nocheck
+{{- end}}
+/*
+* This file is a generated Typescript declaration file for GRPC Gateway, DO NOT MODIFY
+*/
+
+// fetchModuleAPIVersion is the version of the API of the fetch module, which the generated code is compatible with
+export declare const fetchModuleAPIVersion = ` + registry.FetchModuleAPIVersion + `
+
+{{if .NeedsBase64}}export declare function b64Encode(buffer: Uint8Array, start: number, end: number): string
+
+export declare function b64Decode(s: string): Uint8Array
+
+{{end}}export interface InitReq extends RequestInit {
+  pathPrefix?: string
+}
+
+export declare function replacer(key: any, value: any): any
+
+export declare function fetchReq<I, O>(path: string, init?: InitReq): Promise<O>
+
+{{if .NeedsLongRunningOperation}}// OperationError is the google.rpc.Status reported by a failed long-running operation
+export type OperationError = {
+  code?: number
+  message?: string
+  details?: unknown[]
+}
+
+// Operation is the google.longrunning.Operation with response and metadata typed as declared in operation_info
+export type Operation<R, M> = {
+  name?: string
+  metadata?: M
+  done?: boolean
+  error?: OperationError
+  response?: R
+}
+
+// OperationPollOptions controls the backoff when polling a long-running operation, delays are in milliseconds
+export type OperationPollOptions = {
+  initialDelay?: number
+  maxDelay?: number
+  multiplier?: number
+  timeout?: number
+}
+
+// LongRunningOperation holds the operation returned by the server and is able to wait for its result
+export type LongRunningOperation<R, M> = {
+  operation: Operation<R, M>
+  wait: (opts?: OperationPollOptions) => Promise<R>
+}
+
+export declare function newLongRunningOperation<R, M>(operation: Operation<R, M>, getOperationPath: (name: string) => string, init?: InitReq): LongRunningOperation<R, M>
+
+export declare function pollOperation<R, M>(operation: Operation<R, M>, getOperationPath: (name: string) => string, init?: InitReq, opts?: OperationPollOptions): Promise<R>
+
+{{end}}{{if .NeedsServerStreaming}}// NotifyStreamEntityArrival is a callback that will be called on streaming entity arrival
+export type NotifyStreamEntityArrival<T> = (resp: T) => void
+
+export declare function fetchStreamingRequest<S, R>(path: string, callback?: NotifyStreamEntityArrival<R>, init?: InitReq): Promise<void>
+
+{{end}}{{if .NeedsFieldMask}}export declare function fieldMaskFromDiff<T, P extends string>(original: T, updated: Partial<T>, paths: Readonly<Record<string, P>>, prefix?: string): P[]
+
+{{end}}{{if .NeedsURLSearchParams}}type RequestPayload = Record<string, unknown>;
+
+export declare function renderURLSearchParams<T extends RequestPayload>(requestPayload: T, urlPathParams?: string[]): string
+{{end}}`
+
+// jsNamespace is a TypeScript namespace holding values, which is rendered as a nested object in javascript
+type jsNamespace struct {
+	// Name is the name of the namespace inside its parent
+	Name string
+	// Enums are the enums inside the namespace
+	Enums []*data.Enum
+	// Messages are the messages inside the namespace, only their field mask paths are values
+	Messages []*data.Message
+	// Children are the namespaces nested inside the namespace
+	Children []*jsNamespace
+}
+
+// jsNamespaces returns the trees of the namespaces in the file which hold values at runtime, namespaces holding types only are left out
+func jsNamespaces(r *registry.Registry) func(fileData *data.File) []*jsNamespace {
+	return func(fileData *data.File) []*jsNamespace {
+		root := &jsNamespace{}
+		for _, ns := range fileData.Namespaces() {
+			node := root
+			for _, name := range strings.Split(ns.Name, ".") {
+				node = node.child(name)
+			}
+			node.Enums = ns.Enums
+			node.Messages = ns.Messages
+		}
+
+		if root.prune(r) == nil {
+			return nil
+		}
+
+		return root.Children
+	}
+}
+
+func (n *jsNamespace) child(name string) *jsNamespace {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	c := &jsNamespace{Name: name}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// prune removes the namespaces without values from the tree, it returns nil if the namespace itself has none
+func (n *jsNamespace) prune(r *registry.Registry) *jsNamespace {
+	children := make([]*jsNamespace, 0, len(n.Children))
+	for _, c := range n.Children {
+		if pruned := c.prune(r); pruned != nil {
+			children = append(children, pruned)
+		}
+	}
+	n.Children = children
+
+	hasValues := len(n.Enums) > 0 || len(n.Children) > 0
+	for _, m := range n.Messages {
+		hasValues = hasValues || len(fieldMaskPaths(r)(m)) > 0
+	}
+
+	if !hasValues {
+		return nil
+	}
+
+	return n
+}
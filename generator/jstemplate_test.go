@@ -0,0 +1,237 @@
+package generator
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/registry"
+)
+
+var (
+	namespacePattern   = regexp.MustCompile(`^export (?:declare )?namespace ([\w.]+) \{$`)
+	declarationPattern = regexp.MustCompile(`^(function|const|class|interface|type|enum) (\w+)`)
+	staticAliasPattern = regexp.MustCompile(`^static (\w+) = (\w+)$`)
+	jsValuePattern     = regexp.MustCompile(`^export (?:async )?(?:function|const|class) (\w+)`)
+	jsStaticPattern    = regexp.MustCompile(`^  static (\w+)`)
+	// defaultParamPattern matches the parameters with default values, which are declared as optional
+	defaultParamPattern = regexp.MustCompile(`(\w+): ([^,=)]+) = [^,)]+`)
+)
+
+// declaredAPI returns the declarations exported by the typescript file or the declaration file keyed by their
+// qualified names. the functions and the static methods are reduced to their signatures, the constants to their
+// names, and the types and the enums are kept whole, so that the typescript file and the declaration file generated
+// from the same proto are expected to declare the exact same API
+func declaredAPI(content string) map[string]string {
+	api := make(map[string]string)
+	functions := make(map[string]string)
+	classes := make(map[string][]string)
+	aliases := make(map[string][]string)
+
+	lines := strings.Split(content, "\n")
+	namespace := ""
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if match := namespacePattern.FindStringSubmatch(line); match != nil {
+			namespace = match[1]
+			continue
+		}
+		if namespace != "" && line == "}" {
+			namespace = ""
+			continue
+		}
+
+		indent := ""
+		if namespace != "" {
+			indent = "  "
+		}
+		if !strings.HasPrefix(line, indent+"export ") {
+			continue
+		}
+
+		declaration := strings.TrimPrefix(line, indent+"export ")
+		ambient := strings.HasPrefix(declaration, "declare ")
+		declaration = strings.TrimPrefix(declaration, "declare ")
+		async := strings.HasPrefix(declaration, "async ")
+		declaration = strings.TrimPrefix(declaration, "async ")
+		match := declarationPattern.FindStringSubmatch(declaration)
+		if match == nil {
+			continue
+		}
+
+		name := match[2]
+		if namespace != "" {
+			name = namespace + "." + name
+		}
+
+		// the lines of the block the declaration opens, up to the closing brace at the same indentation
+		block := func() []string {
+			body := make([]string, 0)
+			for i+1 < len(lines) && !strings.HasPrefix(lines[i+1], indent+"}") {
+				i++
+				body = append(body, strings.TrimPrefix(lines[i], indent))
+			}
+			i++
+			return body
+		}
+
+		switch match[1] {
+		case "function":
+			if !ambient {
+				// the signature of the implementation may span several lines up to its body
+				for !strings.HasSuffix(declaration, "{") && i+1 < len(lines) {
+					i++
+					if !strings.HasSuffix(declaration, "(") {
+						declaration += " "
+					}
+					declaration += strings.TrimSpace(lines[i])
+				}
+				block()
+			}
+
+			signature := defaultParamPattern.ReplaceAllString(strings.TrimSuffix(declaration, " {"), "$1?: $2")
+			if async && strings.HasSuffix(signature, ")") {
+				// the return type inferred for the async functions without any
+				signature += ": Promise<void>"
+			}
+			functions[match[2]] = strings.TrimPrefix(signature, "function "+match[2])
+			api[name] = signature
+		case "const":
+			if strings.HasSuffix(declaration, "{") {
+				block()
+			}
+			api[name] = "const " + match[2]
+		case "class":
+			members := make([]string, 0)
+			for _, member := range block() {
+				if !strings.HasPrefix(member, "  static ") {
+					continue
+				}
+
+				member = strings.TrimSuffix(strings.TrimPrefix(member, "  "), " {")
+				if alias := staticAliasPattern.FindStringSubmatch(member); alias != nil {
+					// the static member is the standalone function, resolved once all of them are known
+					aliases[alias[2]] = append(aliases[alias[2]], name+"|"+alias[1])
+					continue
+				}
+				members = append(members, member)
+			}
+			classes[name] = members
+		default:
+			declarationLines := []string{strings.TrimSuffix(declaration, " ")}
+			if strings.HasSuffix(declaration, "{") {
+				declarationLines = append(declarationLines, block()...)
+			} else {
+				// the oneof groups follow the base type on their own lines
+				for i+1 < len(lines) && strings.HasPrefix(lines[i+1], indent+"  & ") {
+					i++
+					declarationLines = append(declarationLines, strings.TrimPrefix(lines[i], indent))
+				}
+			}
+			api[name] = strings.Join(declarationLines, "\n")
+		}
+	}
+
+	for function, members := range aliases {
+		for _, member := range members {
+			parts := strings.SplitN(member, "|", 2)
+			classes[parts[0]] = append(classes[parts[0]], "static "+parts[1]+functions[function])
+		}
+	}
+
+	for name, members := range classes {
+		sort.Strings(members)
+		api[name] = strings.Join(append([]string{"class " + name[strings.LastIndex(name, ".")+1:]}, members...), "\n")
+	}
+
+	return api
+}
+
+// runtimeAPI returns the values exported by the javascript file, along with the static members of the classes
+func runtimeAPI(content string) []string {
+	values := make([]string, 0)
+	class := ""
+	for _, line := range strings.Split(content, "\n") {
+		if match := jsValuePattern.FindStringSubmatch(line); match != nil {
+			values = append(values, match[1])
+			class = ""
+			if strings.HasPrefix(line, "export class ") {
+				class = match[1]
+			}
+			continue
+		}
+
+		if match := jsStaticPattern.FindStringSubmatch(line); match != nil && class != "" {
+			values = append(values, class+"."+match[1])
+		}
+	}
+	sort.Strings(values)
+
+	return values
+}
+
+// runtimeValues returns the values the typescript declarations export at runtime in the same form as runtimeAPI,
+// the namespaces holding values are exported as the objects named after their first segment
+func runtimeValues(api map[string]string) []string {
+	seen := make(map[string]bool)
+	values := make([]string, 0)
+	add := func(value string) {
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+
+	for name, declaration := range api {
+		lines := strings.Split(declaration, "\n")
+		kind := strings.SplitN(lines[0], " ", 2)[0]
+		if kind != "function" && kind != "const" && kind != "class" && kind != "enum" {
+			continue
+		}
+
+		add(strings.SplitN(name, ".", 2)[0])
+		if kind == "class" {
+			for _, member := range lines[1:] {
+				add(name + "." + strings.TrimSuffix(strings.SplitN(strings.TrimPrefix(member, "static "), "(", 2)[0], " "))
+			}
+		}
+	}
+	sort.Strings(values)
+
+	return values
+}
+
+// TestGeneratedFilesAPI checks the javascript files and the declaration files generated with target js declare the
+// same API as the typescript files, for every service style and nested type naming with all the features turned on
+func TestGeneratedFilesAPI(t *testing.T) {
+	for _, serviceStyle := range []string{registry.ServiceStyleClass, registry.ServiceStyleFunctions, registry.ServiceStyleFunctionsAndClass} {
+		for _, nestedTypeNaming := range []string{registry.NestedTypeNamingConcat, registry.NestedTypeNamingUnderscore, registry.NestedTypeNamingNamespace} {
+			t.Run(serviceStyle+" "+nestedTypeNaming, func(t *testing.T) {
+				opts := DefaultOptions()
+				opts.ServiceStyle = serviceStyle
+				opts.NestedTypeNaming = nestedTypeNaming
+				opts.FieldMaskDepth = 2
+				ts, err := generateFixture(t, "api.textpb", opts)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+
+				opts.Target = registry.TargetJS
+				js, err := generateFixture(t, "api.textpb", opts)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+
+				for _, name := range []string{"library/library.pb", "fetch.pb"} {
+					tsAPI := declaredAPI(ts[name+".ts"])
+					assert.NotEmpty(t, tsAPI, name)
+					assert.Equal(t, tsAPI, declaredAPI(js[name+dtsExtension]), "API declared by %s", name+dtsExtension)
+					assert.Equal(t, runtimeValues(tsAPI), runtimeAPI(js[name+jsExtension]), "values exported by %s", name+jsExtension)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package generator
+
+import (
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/registry"
+)
+
+// Options are the typed equivalent of the plugin parameters, for generating from Go without going through protoc
+type Options struct {
+	registry.Options
+	// EnableStylingCheck enables both eslint and tsc check for the generated code
+	EnableStylingCheck bool
+	// LintStrict turns the lint issues found in the protos into generation errors instead of warnings
+	LintStrict bool
+}
+
+// DefaultOptions returns the options with the defaults of the plugin parameters
+func DefaultOptions() Options {
+	return Options{Options: registry.DefaultOptions()}
+}
+
+// ParseOptions validates the plugin parameters and turns them into the options
+func ParseOptions(paramsMap map[string]string) (*Options, error) {
+	registryOpts, err := registry.ParseOptions(paramsMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Options{
+		Options:            *registryOpts,
+		EnableStylingCheck: paramsMap[EnableStylingCheckOption] == "true",
+		LintStrict:         paramsMap[LintStrictOption] == "true",
+	}, nil
+}
@@ -19,48 +19,143 @@ import (
 
 const tmpl = `
 {{define "dependencies"}}
-{{range .}}import * as {{.ModuleIdentifier}} from "{{.SourceFile}}"
+{{range .}}import {{if .TypeOnly}}type {{end}}* as {{.ModuleIdentifier}} from "{{.SourceFile}}"
 {{end}}{{end}}
 
+{{define "fetchModuleAPIVersion"}}
+// fails to type check if the fetch module package doesn't implement the API version the file has been generated for
+type _FetchModuleAPIVersion<V extends {{.}} = typeof fm.fetchModuleAPIVersion> = V
+
+{{end}}
+
 {{define "enums"}}
 {{range .}}export enum {{.Name}} {
 {{- range .Values}}
-  {{.}} = "{{.}}",
+  {{propertyName .}} = "{{.}}",
 {{- end}}
 }
 
 {{end}}{{end}}
 
-{{define "messages"}}{{range .}}
+{{define "messages"}}{{range .}}{{include "messageType" .}}
+{{- if fieldMaskPaths .}}{{include "fieldMaskPaths" .}}{{end}}
+{{end}}{{end}}
+
+{{define "messageType"}}
 {{- if .HasOneOfFields}}
 type Base{{.Name}} = {
 {{- range .NonOneOfFields}}
-  {{fieldName .Name}}?: {{tsType .}}
+  {{fieldName .Name | propertyName}}?: {{tsType .}}
 {{- end}}
 }
 
 export type {{.Name}} = Base{{.Name}}
-{{range $groupId, $fields := .OneOfFieldsGroups}}  & OneOf<{ {{range $index, $field := $fields}}{{fieldName $field.Name}}: {{tsType $field}}{{if (lt (add $index 1) (len $fields))}}; {{end}}{{end}} }>
+{{range $groupId, $fields := .OneOfFieldsGroups}}  & OneOf<{ {{range $index, $field := $fields}}{{fieldName $field.Name | propertyName}}: {{tsType $field}}{{if (lt (add $index 1) (len $fields))}}; {{end}}{{end}} }>
 {{end}}
 {{- else -}}
 export type {{.Name}} = {
 {{- range .Fields}}
-  {{fieldName .Name}}?: {{tsType .}}
+  {{fieldName .Name | propertyName}}?: {{tsType .}}
 {{- end}}
 }
+{{end}}{{end}}
+
+{{define "fieldMaskPaths"}}
+export const {{.Name}}FieldMaskPaths = {
+{{- range fieldMaskPaths .}}
+  "{{.Key}}": "{{.Path}}",
+{{- end}}
+} as const
+
+export type {{.Name}}FieldMaskPath = typeof {{.Name}}FieldMaskPaths[keyof typeof {{.Name}}FieldMaskPaths]
+{{end}}
+
+{{define "namespace"}}export namespace {{.Name}} {
+{{- if .Enums}}
+{{include "enums" .Enums | trim | indentBlock 2}}
+{{- end}}
+{{- if .Messages}}
+{{if .Enums}}
+{{end}}{{include "messages" .Messages | trim | indentBlock 2}}
+{{- end}}
+}
+
+{{end}}
+
+{{define "services"}}
+{{- if renderServiceFunctions}}{{include "functions" . | trimPrefix "\n"}}{{end}}
+{{- if and renderServiceClasses renderServiceFunctions}}{{include "composedClasses" .}}
+{{- else if renderServiceClasses}}{{include "classes" .}}{{end}}
+{{- end}}
+
+{{define "functions"}}{{range $service := .}}
+{{- range $method := .Methods}}
+{{- if .ServerStreaming}}
+export function {{$service.FunctionName .Name}}(req: {{tsType .Input}}, entityNotifier?: fm.NotifyStreamEntityArrival<{{tsType .Output}}>, initReq?: fm.InitReq): Promise<void> {
+  return fm.fetchStreamingRequest<{{tsType .Input}}, {{tsType .Output}}>(` + "`{{renderURL .}}`" + `, entityNotifier, {...initReq, {{buildInitReq .}}})
+}
+{{range .Signatures}}
+export function {{$service.FunctionName .Name}}({{range .Params}}{{.Name}}: {{tsType .Type}}, {{end}}entityNotifier?: fm.NotifyStreamEntityArrival<{{tsType $method.Output}}>, initReq?: fm.InitReq): Promise<void> {
+  return {{$service.FunctionName $method.Name}}({{signatureRequest .}}, entityNotifier, initReq)
+}
 {{end}}
+{{- else}}
+export function {{$service.FunctionName .Name}}(req: {{tsType .Input}}, initReq?: fm.InitReq): Promise<{{tsType .Output}}> {
+  return fm.fetchReq<{{tsType .Input}}, {{tsType .Output}}>(` + "`{{renderURL .}}`" + `, {...initReq, {{buildInitReq .}}})
+}
+{{if .LongRunning}}
+export function {{$service.FunctionName (print .Name "Operation")}}(req: {{tsType .Input}}, initReq?: fm.InitReq): Promise<fm.LongRunningOperation<{{operationType .LongRunning.ResponseType}}, {{operationType .LongRunning.MetadataType}}>> {
+  return fm.fetchReq<{{tsType .Input}}, fm.Operation<{{operationType .LongRunning.ResponseType}}, {{operationType .LongRunning.MetadataType}}>>(` + "`{{renderURL .}}`" + `, {...initReq, {{buildInitReq .}}}).then(op => fm.newLongRunningOperation(op, (name: string) => ` + "`{{renderOperationURL .LongRunning}}`" + `, initReq))
+}
+{{end}}
+{{- range .Signatures}}
+export function {{$service.FunctionName .Name}}({{range .Params}}{{.Name}}: {{tsType .Type}}, {{end}}initReq?: fm.InitReq): Promise<{{tsType $method.Output}}> {
+  return {{$service.FunctionName $method.Name}}({{signatureRequest .}}, initReq)
+}
+{{end}}
+{{- end}}
+{{- end}}
+{{- end}}{{end}}
+
+{{define "composedClasses"}}{{range $service := .}}
+export class {{.Name}} {
+{{- range $method := .Methods}}
+  static {{.Name}} = {{$service.FunctionName .Name}}
+{{- if and .LongRunning (not .ServerStreaming)}}
+  static {{.Name}}Operation = {{$service.FunctionName (print .Name "Operation")}}
+{{- end}}
+{{- range .Signatures}}
+  static {{.Name}} = {{$service.FunctionName .Name}}
+{{- end}}
+{{- end}}
+}
 {{end}}{{end}}
 
-{{define "services"}}{{range .}}export class {{.Name}} {
-{{- range .Methods}}  
+{{define "classes"}}{{range $service := .}}export class {{.Name}} {
+{{- range $method := .Methods}}  
 {{- if .ServerStreaming }}
   static {{.Name}}(req: {{tsType .Input}}, entityNotifier?: fm.NotifyStreamEntityArrival<{{tsType .Output}}>, initReq?: fm.InitReq): Promise<void> {
     return fm.fetchStreamingRequest<{{tsType .Input}}, {{tsType .Output}}>(` + "`{{renderURL .}}`" + `, entityNotifier, {...initReq, {{buildInitReq .}}})
   }
+{{- range .Signatures}}
+  static {{.Name}}({{range .Params}}{{.Name}}: {{tsType .Type}}, {{end}}entityNotifier?: fm.NotifyStreamEntityArrival<{{tsType $method.Output}}>, initReq?: fm.InitReq): Promise<void> {
+    return {{$service.Name}}.{{$method.Name}}({{signatureRequest .}}, entityNotifier, initReq)
+  }
+{{- end}}
 {{- else }}
   static {{.Name}}(req: {{tsType .Input}}, initReq?: fm.InitReq): Promise<{{tsType .Output}}> {
     return fm.fetchReq<{{tsType .Input}}, {{tsType .Output}}>(` + "`{{renderURL .}}`" + `, {...initReq, {{buildInitReq .}}})
   }
+{{- if .LongRunning}}
+  static {{.Name}}Operation(req: {{tsType .Input}}, initReq?: fm.InitReq): Promise<fm.LongRunningOperation<{{operationType .LongRunning.ResponseType}}, {{operationType .LongRunning.MetadataType}}>> {
+    return fm.fetchReq<{{tsType .Input}}, fm.Operation<{{operationType .LongRunning.ResponseType}}, {{operationType .LongRunning.MetadataType}}>>(` + "`{{renderURL .}}`" + `, {...initReq, {{buildInitReq .}}}).then(op => fm.newLongRunningOperation(op, (name: string) => ` + "`{{renderOperationURL .LongRunning}}`" + `, initReq))
+  }
+{{- end}}
+{{- range .Signatures}}
+  static {{.Name}}({{range .Params}}{{.Name}}: {{tsType .Type}}, {{end}}initReq?: fm.InitReq): Promise<{{tsType $method.Output}}> {
+    return {{$service.Name}}.{{$method.Name}}({{signatureRequest .}}, initReq)
+  }
+{{- end}}
 {{- end}}
 {{- end}}
 }
@@ -74,6 +169,7 @@ export type {{.Name}} = {
 * This file is a generated Typescript file for GRPC Gateway, DO NOT MODIFY
 */
 {{if .Dependencies}}{{- include "dependencies" .StableDependencies -}}{{end}}
+{{- if .FetchModuleAPIVersion}}{{include "fetchModuleAPIVersion" .FetchModuleAPIVersion}}{{end}}
 {{- if .NeedsOneOfSupport}}
 type Absent<T, K extends keyof T> = { [k in Exclude<keyof T, K>]?: undefined };
 type OneOf<T> =
@@ -84,11 +180,23 @@ type OneOf<T> =
         : never)
     : never);
 {{end}}
-{{- if .Enums}}{{include "enums" .Enums}}{{end}}
-{{- if .Messages}}{{include "messages" .Messages}}{{end}}
+{{- if .RootEnums}}{{include "enums" .RootEnums}}{{end}}
+{{- if .RootMessages}}{{include "messages" .RootMessages}}{{end}}
+{{- range .Namespaces}}{{include "namespace" .}}{{end}}
 {{- if .Services}}{{include "services" .Services}}{{end}}
 `
 
+const indexTmpl = `
+{{- if not .EnableStylingCheck}}
+/* eslint-disable */
+// @ts-nocheck
+{{- end}}
+/*
+* This file is a generated Typescript file for GRPC Gateway, DO NOT MODIFY
+*/
+{{range .Exports}}export * as {{.ModuleIdentifier}} from "{{.SourceFile}}"
+{{end}}`
+
 const fetchTmpl = `
 {{- if not .EnableStylingCheck}}
 /* eslint-disable */
@@ -98,7 +206,10 @@ const fetchTmpl = `
 * This file is a generated Typescript file for GRPC Gateway, DO NOT MODIFY
 */
 
-/**
+// fetchModuleAPIVersion is the version of the API of the fetch module, which the generated code is compatible with
+export const fetchModuleAPIVersion = ` + registry.FetchModuleAPIVersion + `
+
+{{if .NeedsBase64}}/**
  * base64 encoder and decoder
  * Copied and adapted from https://github.com/protobufjs/protobuf.js/blob/master/lib/base64/index.js
  */
@@ -199,15 +310,16 @@ function b64Test(s: string): boolean {
 	return /^(?:[A-Za-z0-9+/]{4})*(?:[A-Za-z0-9+/]{2}==|[A-Za-z0-9+/]{3}=)?$/.test(s);
 }
 
-export interface InitReq extends RequestInit {
+{{end}}export interface InitReq extends RequestInit {
   pathPrefix?: string
 }
 
 export function replacer(key: any, value: any): any {
+{{- if .NeedsBase64}}
   if(value && value.constructor === Uint8Array) {
     return b64Encode(value, 0, value.length);
   }
-
+{{end}}
   return value;
 }
 
@@ -222,7 +334,69 @@ export function fetchReq<I, O>(path: string, init?: InitReq): Promise<O> {
   })) as Promise<O>
 }
 
-// NotifyStreamEntityArrival is a callback that will be called on streaming entity arrival
+{{if .NeedsLongRunningOperation}}// OperationError is the google.rpc.Status reported by a failed long-running operation
+export type OperationError = {
+  code?: number
+  message?: string
+  details?: unknown[]
+}
+
+// Operation is the google.longrunning.Operation with response and metadata typed as declared in operation_info
+export type Operation<R, M> = {
+  name?: string
+  metadata?: M
+  done?: boolean
+  error?: OperationError
+  response?: R
+}
+
+// OperationPollOptions controls the backoff when polling a long-running operation, delays are in milliseconds
+export type OperationPollOptions = {
+  initialDelay?: number
+  maxDelay?: number
+  multiplier?: number
+  timeout?: number
+}
+
+// LongRunningOperation holds the operation returned by the server and is able to wait for its result
+export type LongRunningOperation<R, M> = {
+  operation: Operation<R, M>
+  wait: (opts?: OperationPollOptions) => Promise<R>
+}
+
+export function newLongRunningOperation<R, M>(operation: Operation<R, M>, getOperationPath: (name: string) => string, init?: InitReq): LongRunningOperation<R, M> {
+  return {
+    operation,
+    wait: (opts?: OperationPollOptions) => pollOperation<R, M>(operation, getOperationPath, init, opts),
+  }
+}
+
+/**
+ * pollOperation calls GetOperation through the gateway with exponential backoff until the operation is done,
+ * it resolves to the operation response and rejects with the operation error if the operation has failed.
+ **/
+export async function pollOperation<R, M>(operation: Operation<R, M>, getOperationPath: (name: string) => string, init?: InitReq, opts?: OperationPollOptions): Promise<R> {
+  const {initialDelay = 500, maxDelay = 10000, multiplier = 1.5, timeout} = opts || {}
+  const deadline = timeout ? Date.now() + timeout : undefined
+  let delay = initialDelay
+  let op = operation
+  while (!op.done) {
+    if (deadline !== undefined && Date.now() + delay > deadline) {
+      throw new Error(` + "`timed out waiting for operation ${op.name}`" + `)
+    }
+    await new Promise(resolve => setTimeout(resolve, delay))
+    delay = Math.min(delay * multiplier, maxDelay)
+    op = await fetchReq<{name?: string}, Operation<R, M>>(getOperationPath(op.name || ""), {...init, method: "GET", body: undefined})
+  }
+
+  if (op.error) {
+    throw op.error
+  }
+
+  return op.response as R
+}
+
+{{end}}{{if .NeedsServerStreaming}}// NotifyStreamEntityArrival is a callback that will be called on streaming entity arrival
 export type NotifyStreamEntityArrival<T> = (resp: T) => void
 
 /**
@@ -317,11 +491,71 @@ function getNotifyEntityArrivalSink<T>(notifyCallback: NotifyStreamEntityArrival
   })
 }
 
-type Primitive = string | boolean | number;
+{{end}}{{if .NeedsFieldMask}}/**
+ * fieldMaskFromDiff computes the field mask paths for the fields set in updated that differ from original.
+ * paths maps the field names as they are rendered, joined with dots, to the field mask paths, e.g. BookFieldMaskPaths.
+ * Nested messages are compared field by field when they have nested paths,
+ * everything else is compared as a whole and reported with its own path.
+ **/
+export function fieldMaskFromDiff<T, P extends string>(original: T, updated: Partial<T>, paths: Readonly<Record<string, P>>, prefix: string = ""): P[] {
+  const originalObject = (original || {}) as Record<string, unknown>
+  const updatedObject = (updated || {}) as Record<string, unknown>
+
+  return Object.keys(updatedObject).reduce((acc: P[], key: string): P[] => {
+    const keyPath = prefix ? [prefix, key].join(".") : key
+    if (!Object.prototype.hasOwnProperty.call(paths, keyPath)) {
+      return acc
+    }
+
+    const originalValue = originalObject[key]
+    const updatedValue = updatedObject[key]
+    const hasNestedPaths = Object.keys(paths).some(k => k.startsWith(keyPath + "."))
+
+    if (hasNestedPaths && isPlainObject(originalValue) && isPlainObject(updatedValue)) {
+      return [...acc, ...fieldMaskFromDiff(originalValue as Record<string, unknown>, updatedValue as Record<string, unknown>, paths, keyPath)]
+    }
+
+    if (!isEqual(originalValue, updatedValue)) {
+      return [...acc, paths[keyPath]]
+    }
+
+    return acc
+  }, [] as P[])
+}
+
+/**
+ * Checks if given values are deeply equal, plain objects are compared key by key
+ * and arrays including Uint8Array are compared element by element
+ * @param  {unknown} a
+ * @param  {unknown} b
+ * @return {boolean}
+ */
+function isEqual(a: unknown, b: unknown): boolean {
+  if (a === b) {
+    return true
+  }
+
+  if ((Array.isArray(a) && Array.isArray(b)) || (a instanceof Uint8Array && b instanceof Uint8Array)) {
+    const left = a as ArrayLike<unknown>
+    const right = b as ArrayLike<unknown>
+    return left.length === right.length && Array.prototype.every.call(left, (v: unknown, i: number) => isEqual(v, right[i]))
+  }
+
+  if (isPlainObject(a) && isPlainObject(b)) {
+    const left = a as Record<string, unknown>
+    const right = b as Record<string, unknown>
+    const keys = new Set([...Object.keys(left), ...Object.keys(right)])
+    return Array.from(keys).every(k => isEqual(left[k], right[k]))
+  }
+
+  return false
+}
+
+{{end}}{{if .NeedsURLSearchParams}}type Primitive = string | boolean | number;
 type RequestPayload = Record<string, unknown>;
 type FlattenedRequestPayload = Record<string, Primitive | Array<Primitive>>;
 
-/**
+{{end}}{{if or .NeedsFieldMask .NeedsURLSearchParams}}/**
  * Checks if given value is a plain object
  * Logic copied and adapted from below source: 
  * https://github.com/char0n/ramda-adjunct/blob/master/src/isPlainObj.js
@@ -346,7 +580,7 @@ function isPlainObject(value: unknown): boolean {
   return hasObjectConstructor;
 }
 
-/**
+{{end}}{{if .NeedsURLSearchParams}}/**
  * Checks if given value is of a primitive type
  * @param  {unknown} value
  * @return {boolean}
@@ -433,7 +667,7 @@ export function renderURLSearchParams<T extends RequestPayload>(
 
   return new URLSearchParams(urlSearchParams).toString();
 }
-`
+{{end}}`
 
 // GetTemplate gets the templates to for the typescript file
 func GetTemplate(r *registry.Registry) *template.Template {
@@ -441,19 +675,47 @@ func GetTemplate(r *registry.Registry) *template.Template {
 	t = t.Funcs(sprig.TxtFuncMap())
 
 	t = t.Funcs(template.FuncMap{
-		"include": include(t),
-		"tsType": func(fieldType data.Type) string {
-			return tsType(r, fieldType)
-		},
-		"renderURL":    renderURL(r),
-		"buildInitReq": buildInitReq,
-		"fieldName":    fieldName(r),
+		"include":            include(t),
+		"buildInitReq":       buildInitReq,
+		"renderOperationURL": renderOperationURL,
+		"indentBlock":        indentBlock,
+		"propertyName":       data.QuotePropertyName,
 	})
 
+	t = t.Funcs(fileFuncs(r, data.NewFile()))
 	t = template.Must(t.Parse(tmpl))
+	template.Must(t.New("file" + jsExtension).Parse(jsTmpl))
+	template.Must(t.New("file" + dtsExtension).Parse(dtsTmpl))
 	return t
 }
 
+// fileFuncs returns the template functions depending on the file being rendered and the parameters overridden for it,
+// they need to be rebound to the template before rendering each file
+func fileFuncs(r *registry.Registry, fileData *data.File) template.FuncMap {
+	return template.FuncMap{
+		"renderURL":        renderURL(r),
+		"fieldName":        fieldName(r),
+		"fieldMaskPaths":   fieldMaskPaths(r),
+		"signatureRequest": signatureRequest(r),
+		"renderServiceFunctions": func() bool {
+			return r.ServiceStyle != registry.ServiceStyleClass
+		},
+		"renderServiceClasses": func() bool {
+			return r.ServiceStyle != registry.ServiceStyleFunctions
+		},
+		"jsNamespaces": jsNamespaces(r),
+		"tsType": func(fieldType data.Type) string {
+			return tsType(r, fileData, fieldType)
+		},
+		"operationType": func(arg *data.MethodArgument) string {
+			if arg == nil {
+				return "unknown"
+			}
+			return tsType(r, fileData, arg)
+		},
+	}
+}
+
 func fieldName(r *registry.Registry) func(name string) string {
 	return func(name string) string {
 		if r.UseProtoNames {
@@ -464,6 +726,79 @@ func fieldName(r *registry.Registry) func(name string) string {
 	}
 }
 
+// fieldMaskPath is a field mask path of a message along with the field names leading to it in TypeScript
+type fieldMaskPath struct {
+	// Key joins the names of the fields as they are rendered in the messages with dots
+	Key string
+	// Path is the field mask path the gateway expects for the field
+	Path string
+}
+
+// fieldMaskPaths returns the field mask paths of the message nested down to the configured depth.
+// paths don't go into repeated fields, maps and well known types as they can only be masked as a whole
+func fieldMaskPaths(r *registry.Registry) func(message *data.Message) []fieldMaskPath {
+	return func(message *data.Message) []fieldMaskPath {
+		return collectFieldMaskPaths(r, r, message, fieldMaskPath{}, r.FieldMaskDepth)
+	}
+}
+
+// collectFieldMaskPaths collects the paths of the message under the prefix. the depth comes from the file being
+// rendered, while the keys of the nested messages follow the registry of the file the message is defined in,
+// as use_proto_names can be overridden per file
+func collectFieldMaskPaths(r, messageRegistry *registry.Registry, message *data.Message, prefix fieldMaskPath, depth int) []fieldMaskPath {
+	if depth <= 0 {
+		return nil
+	}
+
+	keyFn := fieldName(messageRegistry)
+	paths := make([]fieldMaskPath, 0, len(message.Fields))
+	for _, f := range message.Fields {
+		path := fieldMaskPath{
+			Key:  prefix.Key + keyFn(f.Name),
+			Path: prefix.Path + fieldMaskPathSegment(f),
+		}
+		paths = append(paths, path)
+
+		if f.IsRepeated {
+			continue
+		}
+
+		typeInfo, ok := r.Types[f.Type]
+		if !ok || typeInfo.Message == nil || typeInfo.Package == "google.protobuf" {
+			continue
+		}
+
+		nestedPrefix := fieldMaskPath{Key: path.Key + ".", Path: path.Path + "."}
+		nestedRegistry := r.ForFile(typeInfo.File, typeInfo.Package)
+		paths = append(paths, collectFieldMaskPaths(r, nestedRegistry, typeInfo.Message, nestedPrefix, depth-1)...)
+	}
+
+	return paths
+}
+
+// fieldMaskPathSegment is the lowerCamel form of the proto name the same way as protojson does, which is what the JSON
+// encoding of google.protobuf.FieldMask takes regardless of json_name and use_proto_names. as the segments don't depend
+// on the options of the file, the paths of the messages nested from other files are the same as in their own files
+func fieldMaskPathSegment(f *data.Field) string {
+	segment := make([]byte, 0, len(f.Name))
+	afterUnderscore := false
+	for i := 0; i < len(f.Name); i++ {
+		c := f.Name[i]
+		if c == '_' {
+			afterUnderscore = true
+			continue
+		}
+
+		if afterUnderscore && 'a' <= c && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		segment = append(segment, c)
+		afterUnderscore = false
+	}
+
+	return string(segment)
+}
+
 func renderURL(r *registry.Registry) func(method data.Method) string {
 	fieldNameFn := fieldName(r)
 	return func(method data.Method) string {
@@ -503,6 +838,82 @@ func renderURL(r *registry.Registry) func(method data.Method) string {
 	}
 }
 
+// renderOperationURL renders the GetOperation path with the operation name, the name
+// returned from the server already contains the segments matched by the path pattern
+func renderOperationURL(operation data.LongRunningOperation) string {
+	reg := regexp.MustCompile("{name(=[^}]*)?}")
+	return reg.ReplaceAllLiteralString(operation.GetOperationURL, "${name}")
+}
+
+// signatureRequestNode is a node in the request object literal assembled from signature params. a node can have both
+// a value and children when a signature lists a field along with fields nested in it, e.g. book and book.title
+type signatureRequestNode struct {
+	key      string
+	value    string
+	children []*signatureRequestNode
+}
+
+func (n *signatureRequestNode) child(key string) *signatureRequestNode {
+	for _, c := range n.children {
+		if c.key == key {
+			return c
+		}
+	}
+
+	c := &signatureRequestNode{key: key}
+	n.children = append(n.children, c)
+	return c
+}
+
+// render spreads the value of the node before the children so that the nested fields override the ones of the value,
+// the nodes without a value in between spread the fields of the value of their ancestor, which is the base
+func (n *signatureRequestNode) render(base string) string {
+	if len(n.children) == 0 {
+		return n.value
+	}
+
+	if n.value != "" {
+		base = n.value
+	}
+
+	fields := make([]string, 0, len(n.children)+1)
+	if base != "" {
+		fields = append(fields, "..."+base)
+	}
+	for _, c := range n.children {
+		childBase := ""
+		if base != "" && strings.HasPrefix(c.key, `"`) {
+			childBase = base + "?.[" + c.key + "]"
+		} else if base != "" {
+			childBase = base + "?." + c.key
+		}
+		fields = append(fields, c.key+": "+c.render(childBase))
+	}
+
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// signatureRequest renders the object literal of the request with the params of the signature
+func signatureRequest(r *registry.Registry) func(signature data.MethodSignature) string {
+	fieldNameFn := fieldName(r)
+	return func(signature data.MethodSignature) string {
+		root := &signatureRequestNode{}
+		for _, param := range signature.Params {
+			node := root
+			for _, p := range param.Path {
+				node = node.child(data.QuotePropertyName(fieldNameFn(p)))
+			}
+			node.value = param.Name
+		}
+
+		if len(root.children) == 0 {
+			return "{}"
+		}
+
+		return root.render("")
+	}
+}
+
 func buildInitReq(method data.Method) string {
 	httpMethod := method.HTTPMethod
 	m := `method: "` + httpMethod + `"`
@@ -517,10 +928,32 @@ func buildInitReq(method data.Method) string {
 
 }
 
+// GetIndexTemplate returns the go template for the index files of the packages
+func GetIndexTemplate() *template.Template {
+	t := template.New("index")
+	return template.Must(t.Parse(indexTmpl))
+}
+
 // GetFetchModuleTemplate returns the go template for fetch module
 func GetFetchModuleTemplate() *template.Template {
 	t := template.New("fetch")
-	return template.Must(t.Parse(fetchTmpl))
+	t = template.Must(t.Parse(fetchTmpl))
+	template.Must(t.New("fetch" + jsExtension).Parse(fetchJSTmpl))
+	template.Must(t.New("fetch" + dtsExtension).Parse(fetchDTSTmpl))
+	return t
+}
+
+// indentBlock indents every non empty line of the block with the number of spaces
+func indentBlock(spaces int, block string) string {
+	pad := strings.Repeat(" ", spaces)
+	lines := strings.Split(block, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
 }
 
 // include is the include template functions copied from
@@ -535,12 +968,12 @@ func include(t *template.Template) func(name string, data interface{}) (string,
 	}
 }
 
-func tsType(r *registry.Registry, fieldType data.Type) string {
+func tsType(r *registry.Registry, fileData *data.File, fieldType data.Type) string {
 	info := fieldType.GetType()
 	typeInfo, ok := r.Types[info.Type]
 	if ok && typeInfo.IsMapEntry {
-		keyType := tsType(r, typeInfo.KeyType)
-		valueType := tsType(r, typeInfo.ValueType)
+		keyType := tsType(r, fileData, typeInfo.KeyType)
+		valueType := tsType(r, fileData, typeInfo.ValueType)
 
 		return fmt.Sprintf("{[key: %s]: %s}", keyType, valueType)
 	}
@@ -551,7 +984,7 @@ func tsType(r *registry.Registry, fieldType data.Type) string {
 	} else if !info.IsExternal {
 		typeStr = typeInfo.PackageIdentifier
 	} else {
-		typeStr = data.GetModuleName(typeInfo.Package, typeInfo.File) + "." + typeInfo.PackageIdentifier
+		typeStr = fileData.ModuleIdentifier(typeInfo.Package, typeInfo.File) + "." + typeInfo.PackageIdentifier
 	}
 
 	if info.IsRepeated {
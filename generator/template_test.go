@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/registry"
+)
+
+var exportPattern = regexp.MustCompile(`(?m)^export (?:declare )?(?:async )?(function|const|class|interface|type) (\w+)`)
+
+// exportedNames returns the names of the values and the types exported by the rendered module
+func exportedNames(t *testing.T, templateName string, fetchModule *data.FetchModule) (values []string, types []string) {
+	w := bytes.NewBufferString("")
+	err := GetFetchModuleTemplate().ExecuteTemplate(w, templateName, fetchModule)
+	assert.NoError(t, err)
+
+	return exports(w.String())
+}
+
+// exports returns the names of the values and the types exported by the module source
+func exports(content string) (values []string, types []string) {
+	for _, match := range exportPattern.FindAllStringSubmatch(content, -1) {
+		if match[1] == "interface" || match[1] == "type" {
+			types = append(types, match[2])
+		} else {
+			values = append(values, match[2])
+		}
+	}
+	sort.Strings(values)
+	sort.Strings(types)
+
+	return values, types
+}
+
+// TestFetchModuleExports checks the javascript and the declaration file of the fetch module are in line with the
+// typescript one for every set of features, which covers fetch_module_features=all and any subset used
+func TestFetchModuleExports(t *testing.T) {
+	for features := 0; features < 1<<5; features++ {
+		fetchModule := &data.FetchModule{
+			NeedsBase64:               features&1 != 0,
+			NeedsServerStreaming:      features&2 != 0,
+			NeedsURLSearchParams:      features&4 != 0,
+			NeedsLongRunningOperation: features&8 != 0,
+			NeedsFieldMask:            features&16 != 0,
+		}
+
+		tsValues, tsTypes := exportedNames(t, "fetch", fetchModule)
+		jsValues, jsTypes := exportedNames(t, "fetch"+jsExtension, fetchModule)
+		dtsValues, dtsTypes := exportedNames(t, "fetch"+dtsExtension, fetchModule)
+
+		assert.NotEmpty(t, tsValues, "features %+v", fetchModule)
+		assert.Equal(t, tsValues, jsValues, "values exported by fetch.js with features %+v", fetchModule)
+		assert.Empty(t, jsTypes, "types exported by fetch.js with features %+v", fetchModule)
+		assert.Equal(t, tsValues, dtsValues, "values declared by fetch.d.ts with features %+v", fetchModule)
+		assert.Equal(t, tsTypes, dtsTypes, "types declared by fetch.d.ts with features %+v", fetchModule)
+	}
+}
+
+func TestSignatureRequest(t *testing.T) {
+	r, err := registry.NewRegistryFromOptions(registry.DefaultOptions())
+	assert.NoError(t, err)
+
+	param := func(name string, path ...string) *data.SignatureParam {
+		return &data.SignatureParam{Name: name, Path: path}
+	}
+
+	tests := []struct {
+		name     string
+		params   []*data.SignatureParam
+		expected string
+	}{
+		{name: "no params", expected: "{}"},
+		{name: "fields", params: []*data.SignatureParam{param("parent", "parent"), param("book", "book")}, expected: "{parent: parent, book: book}"},
+		{name: "nested fields", params: []*data.SignatureParam{param("bookTitle", "book", "title"), param("bookAuthorName", "book", "author_name")}, expected: "{book: {title: bookTitle, authorName: bookAuthorName}}"},
+		{name: "field and nested field", params: []*data.SignatureParam{param("book", "book"), param("bookTitle", "book", "title")}, expected: "{book: {...book, title: bookTitle}}"},
+		{name: "nested field and field", params: []*data.SignatureParam{param("bookTitle", "book", "title"), param("book", "book")}, expected: "{book: {...book, title: bookTitle}}"},
+		{
+			name:     "field and deeply nested field",
+			params:   []*data.SignatureParam{param("parent", "parent"), param("book", "book"), param("bookAuthorName", "book", "author", "name")},
+			expected: "{parent: parent, book: {...book, author: {...book?.author, name: bookAuthorName}}}",
+		},
+		{
+			name:     "field and nested field quoted",
+			params:   []*data.SignatureParam{param("book", "book"), param("bookDefaultName", "book", "default", "name")},
+			expected: `{book: {...book, "default": {...book?.["default"], name: bookDefaultName}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, signatureRequest(r)(data.MethodSignature{Params: tt.params}))
+		})
+	}
+}
@@ -10,54 +10,91 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/generator"
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/registry"
 	"github.com/pkg/errors"
 )
 
-func decodeReq() *plugin.CodeGeneratorRequest {
+func decodeReq() (*plugin.CodeGeneratorRequest, error) {
 	req := &plugin.CodeGeneratorRequest{}
 	data, err := ioutil.ReadAll(os.Stdin)
 	if err != nil {
-		panic(err)
+		return nil, errors.Wrap(err, "error reading code generator request")
 	}
 	err = proto.Unmarshal(data, req)
 	if err != nil {
-		panic(err)
+		return nil, errors.Wrap(err, "error decoding code generator request")
 	}
-	return req
+	return req, nil
 }
 
-func encodeResponse(resp proto.Message) {
+func encodeResponse(resp proto.Message) error {
 	data, err := proto.Marshal(resp)
 	if err != nil {
-		panic(err)
+		return errors.Wrap(err, "error encoding code generator response")
 	}
 	_, err = os.Stdout.Write(data)
 	if err != nil {
-		panic(err)
+		return errors.Wrap(err, "error writing code generator response")
 	}
+	return nil
 }
 
 func main() {
-	req := decodeReq()
-	paramsMap := getParamsMap(req)
-	err := configureLogging(paramsMap)
+	resp, err := generate()
+	if err != nil {
+		// errors are reported back to protoc, which will print it out along with the plugin name
+		resp = &plugin.CodeGeneratorResponse{Error: proto.String(errorMessage(err))}
+	}
+
+	err = encodeResponse(resp)
+	if err != nil {
+		log.Errorf("%v", err)
+		os.Exit(1)
+	}
+	log.Debug("generation finished")
+}
+
+func generate() (*plugin.CodeGeneratorResponse, error) {
+	req, err := decodeReq()
+	if err != nil {
+		return nil, err
+	}
+
+	paramsMap, err := registry.LoadConfigFile(getParamsMap(req))
+	if err != nil {
+		return nil, err
+	}
+
+	// the parameters are validated before the logging is configured from them
+	err = registry.ValidateParams(paramsMap)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	err = configureLogging(paramsMap)
+	if err != nil {
+		return nil, err
 	}
 
 	g, err := generator.New(paramsMap)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	log.Debug("Starts generating file request")
-	resp, err := g.Generate(req)
-	if err != nil {
-		panic(err)
+	return g.Generate(req)
+}
+
+// errorMessage prefers the message of the element error if there is one, which tells users
+// where the error is inside the proto files without the noise of the whole error chain
+func errorMessage(err error) string {
+	log.Debugf("generation failed: %+v", err)
+	var elementErr *registry.ElementError
+	if errors.As(err, &elementErr) {
+		return elementErr.Error()
 	}
 
-	encodeResponse(resp)
-	log.Debug("generation finished")
+	return err.Error()
 }
 
 func configureLogging(paramsMap map[string]string) error {
@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
+)
+
+// loadGrpcAPIConfiguration reads the http rules from the gateway service config YAML,
+// which is the same file supplied to grpc-gateway with grpc_api_configuration.
+// it returns the rules keyed by the selector of the method they apply to
+func loadGrpcAPIConfiguration(fileName string) (map[string]*annotations.HttpRule, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading gRPC API configuration %s", fileName)
+	}
+
+	var config struct {
+		HTTP interface{} `yaml:"http"`
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, errors.Wrapf(err, "error parsing gRPC API configuration %s", fileName)
+	}
+
+	rules := make(map[string]*annotations.HttpRule)
+	if config.HTTP == nil {
+		return rules, nil
+	}
+
+	// converting to JSON so that protojson can take care of the HttpRule oneof and field names
+	httpJSON, err := json.Marshal(config.HTTP)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error converting http section of gRPC API configuration %s", fileName)
+	}
+
+	http := &annotations.Http{}
+	if err := protojson.Unmarshal(httpJSON, http); err != nil {
+		return nil, errors.Wrapf(err, "error parsing http rules of gRPC API configuration %s", fileName)
+	}
+
+	for _, rule := range http.GetRules() {
+		selector := rule.GetSelector()
+		if selector == "" {
+			return nil, errors.Errorf("http rule without selector found in gRPC API configuration %s", fileName)
+		}
+
+		if _, ok := rules[selector]; ok {
+			return nil, errors.Errorf("duplicated http rule for %s in gRPC API configuration %s", selector, fileName)
+		}
+
+		rules[selector] = rule
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadGrpcAPIConfiguration(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expected    map[string]string
+		expectedErr string
+	}{
+		{
+			name:     "rules",
+			content:  "http:\n  rules:\n  - selector: a.B.C\n    get: /c\n  - selector: a.B.D\n    post: /d\n    body: \"*\"\n",
+			expected: map[string]string{"a.B.C": "/c", "a.B.D": "/d"},
+		},
+		{name: "no http section", content: "type: google.api.Service\n", expected: map[string]string{}},
+		{name: "no selector", content: "http:\n  rules:\n  - get: /c\n", expectedErr: "http rule without selector found in gRPC API configuration"},
+		{
+			name:        "duplicated selector",
+			content:     "http:\n  rules:\n  - selector: a.B.C\n    get: /c\n  - selector: a.B.C\n    get: /d\n",
+			expectedErr: "duplicated http rule for a.B.C in gRPC API configuration",
+		},
+		{name: "unknown field", content: "http:\n  rules:\n  - selector: a.B.C\n    fetch: /c\n", expectedErr: "error parsing http rules of gRPC API configuration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := loadGrpcAPIConfiguration(writeConfigFile(t, tt.content))
+			if tt.expectedErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.expectedErr)
+				}
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			paths := make(map[string]string, len(rules))
+			for selector, rule := range rules {
+				_, paths[selector], _ = getHTTPMethodPath(rule)
+			}
+			assert.Equal(t, tt.expected, paths)
+		})
+	}
+}
+
+func TestAnalyseGrpcAPIConfiguration(t *testing.T) {
+	r := newTestRegistry(t, func(opts *Options) {
+		opts.GrpcAPIConfiguration = filepath.Join("testdata", "api_config.yaml")
+	})
+
+	filesData, err := analyseFixture(t, r, "api_config.textpb")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	methods := make(map[string]string)
+	for _, method := range filesData["library/library.proto"].Services[0].Methods {
+		methods[method.Name] = method.HTTPMethod + " " + method.URL
+	}
+	assert.Equal(t, map[string]string{
+		"ListBooks":  "GET /v1/{parent}/books",
+		"MoveBook":   "MOVE /v1/{name}",
+		"GetBook":    "GET /v1/{name}",
+		"DeleteBook": "POST /library.Library/DeleteBook",
+	}, methods)
+}
+
+func TestAnalyseGrpcAPIConfigurationConflict(t *testing.T) {
+	r := newTestRegistry(t, func(opts *Options) {
+		opts.GrpcAPIConfiguration = writeConfigFile(t, "http:\n  rules:\n  - selector: library.Library.GetBook\n    get: /v2/{name}\n")
+	})
+
+	_, err := analyseFixture(t, r, "api_config.textpb")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "library.Library.GetBook: http rules found in both the annotation and the gRPC API configuration")
+	}
+}
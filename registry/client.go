@@ -0,0 +1,32 @@
+package registry
+
+import (
+	log "github.com/sirupsen/logrus" // nolint: depguard
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+// newClientFile returns the file the services of the proto file are analysed into when client_file_suffix is set
+func (r *Registry) newClientFile(fileData *data.File) *data.File {
+	clientFile := data.NewFile()
+	clientFile.Name = fileData.Name
+	clientFile.Package = fileData.Package
+	clientFile.TSFileName = r.getClientFileName(fileData.TSFileName)
+
+	return clientFile
+}
+
+// analyseClientFileTypeDependencies marks all the types referenced by the services external to the client file,
+// including the ones defined in the same proto file, which will be imported from the file of the types
+func (r *Registry) analyseClientFileTypeDependencies(clientFile *data.File) {
+	for _, t := range clientFile.PackageNonScalarType {
+		typeInfo := t.GetType()
+		if typeInfo.IsExternal {
+			continue
+		}
+
+		log.Debugf("type %s is imported into client file %s", typeInfo.Type, clientFile.TSFileName)
+		clientFile.ExternalDependingTypes = append(clientFile.ExternalDependingTypes, typeInfo.Type)
+		t.SetExternal(true)
+	}
+}
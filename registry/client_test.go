@@ -0,0 +1,46 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyseClientFiles(t *testing.T) {
+	r := newTestRegistry(t, func(opts *Options) {
+		opts.ClientFileSuffix = ".client.ts"
+	})
+
+	filesData, err := analyseFixture(t, r, "exclude.textpb")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	fileData := filesData["counter/counter.proto"]
+	assert.Equal(t, "counter/counter.pb.ts", fileData.TSFileName)
+	assert.Empty(t, fileData.Services)
+	// the types file never imports the fetch module nor the client file
+	assert.Empty(t, fileData.Dependencies)
+
+	clientFile := fileData.Client
+	if !assert.NotNil(t, clientFile) {
+		t.FailNow()
+	}
+	assert.Equal(t, "counter/counter.client.ts", clientFile.TSFileName)
+	assert.Equal(t, "counter/counter.proto", clientFile.Name)
+	assert.Len(t, clientFile.Services, 1)
+	assert.Empty(t, clientFile.Messages)
+
+	sourceFiles := make([]string, 0)
+	for _, dependency := range clientFile.StableDependencies() {
+		sourceFiles = append(sourceFiles, dependency.SourceFile)
+	}
+	assert.ElementsMatch(t, []string{"../fetch.pb", "./counter.pb"}, sourceFiles)
+}
+
+func TestClientFileSuffixRejected(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ClientFileSuffix = opts.FileSuffix
+	_, err := NewRegistryFromOptions(opts)
+	assert.EqualError(t, err, "invalid client_file_suffix .pb.ts, it needs to be different from file_suffix")
+}
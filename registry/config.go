@@ -0,0 +1,307 @@
+package registry
+
+import (
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus" // nolint: depguard
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// configImportMappings is the key of the import mappings in the config file, it maps the proto paths to the import
+	// specifiers in the same way as the M parameters
+	configImportMappings = "M"
+	// configOverrides is the key of the list of the parameters overridden per package or per file in the config file
+	configOverrides = "overrides"
+	// configOverridePackage is the key selecting the package an override applies to
+	configOverridePackage = "package"
+	// configOverrideFile is the key selecting the file an override applies to
+	configOverrideFile = "file"
+)
+
+// overridableParams are the parameters which can be overridden per package or per file, as they only affect the
+// files they are applied to
+var overridableParams = []string{UseProtoNames, FieldMaskDepth, ServiceStyle}
+
+// Override is a set of parameters applied to the files of a package or to a single file only
+type Override struct {
+	// Package is the proto package the override applies to, empty if it applies to a file
+	Package string
+	// File is the proto file the override applies to, empty if it applies to a package
+	File string
+	// Params are the overridden parameters
+	Params map[string]string
+}
+
+// config is the content of the config file
+type config struct {
+	// params are the parameters in the same form as the ones specified in the protoc invocation
+	params map[string]string
+	// lists are the list parameters, which are kept as the lists they are written as in the file
+	lists map[string][]string
+	// overrides are the parameters overridden per package or per file
+	overrides []*Override
+}
+
+// LoadConfigFile reads the parameters from the config file if the config parameter is specified, and returns them
+// merged with the other parameters. the parameters in the config file take precedence over the ones specified in the
+// protoc invocation, in the same way as the overrides in the file take precedence over the parameters. the list
+// parameters are left out, ParseOptions takes them from the config file as they are
+func LoadConfigFile(paramsMap map[string]string) (map[string]string, error) {
+	fileName, ok := paramsMap[ConfigFile]
+	if !ok || fileName == "" {
+		return paramsMap, nil
+	}
+
+	c, err := readConfigFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]string, len(paramsMap)+len(c.params))
+	for key, value := range paramsMap {
+		params[key] = value
+	}
+
+	for key, value := range c.params {
+		if flagValue, ok := params[key]; ok && flagValue != value {
+			log.Warnf("parameter %s=%s has been overridden with %s in config file %s", key, flagValue, value, fileName)
+		}
+		params[key] = value
+	}
+
+	for key, list := range c.lists {
+		if flagValue, ok := params[key]; ok {
+			log.Warnf("parameter %s=%s has been overridden with %s in config file %s", key, flagValue, list, fileName)
+			delete(params, key)
+		}
+	}
+
+	return params, nil
+}
+
+// loadConfig returns the content of the config file if the config parameter is specified
+func loadConfig(paramsMap map[string]string) (*config, error) {
+	fileName, ok := paramsMap[ConfigFile]
+	if !ok || fileName == "" {
+		return &config{}, nil
+	}
+
+	return readConfigFile(fileName)
+}
+
+// readConfigFile parses the YAML or JSON config file and validates it against the schema,
+// the errors are reported with the line they are found at
+func readConfigFile(fileName string) (*config, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading config file %s", fileName)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, errors.Wrapf(err, "error parsing config file %s", fileName)
+	}
+
+	c := &config{
+		params:    make(map[string]string),
+		lists:     make(map[string][]string),
+		overrides: make([]*Override, 0),
+	}
+	if len(document.Content) == 0 {
+		return c, nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, configError(fileName, root, "the config needs to be a mapping of the parameters")
+	}
+
+	for i := 0; i < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case configImportMappings:
+			err = readConfigImportMappings(fileName, value, c.params)
+		case configOverrides:
+			c.overrides, err = readConfigOverrides(fileName, value)
+		default:
+			err = readConfigParam(fileName, key, value, c.params, c.lists)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// readConfigParam reads the parameter into params, or into lists if it is a list parameter. the list parameters
+// are rejected if lists is nil, as none of them can be overridden
+func readConfigParam(fileName string, key, value *yaml.Node, params map[string]string, lists map[string][]string) error {
+	p, ok := LookupParam(key.Value)
+	if !ok {
+		return configError(fileName, key, "%s", unknownParamMessage(key.Value))
+	}
+
+	if p.Name == ConfigFile {
+		return configError(fileName, key, "%s cannot be specified in the config file", ConfigFile)
+	}
+
+	switch p.Kind {
+	case ParamKindBool:
+		var b bool
+		if value.Kind != yaml.ScalarNode || value.Decode(&b) != nil {
+			return configError(fileName, value, "%s needs to be a boolean", key.Value)
+		}
+		params[key.Value] = strconv.FormatBool(b)
+	case ParamKindInt:
+		var i int
+		if value.Kind != yaml.ScalarNode || value.Decode(&i) != nil {
+			return configError(fileName, value, "%s needs to be an integer", key.Value)
+		}
+		params[key.Value] = strconv.Itoa(i)
+	case ParamKindList:
+		if lists == nil {
+			return configError(fileName, key, "%s cannot be overridden per package or file, it needs to be one of %s", key.Value, strings.Join(overridableParams, ", "))
+		}
+
+		var list []string
+		if value.Kind == yaml.ScalarNode {
+			list = []string{value.Value}
+		} else if value.Kind != yaml.SequenceNode || value.Decode(&list) != nil {
+			return configError(fileName, value, "%s needs to be a list of strings", key.Value)
+		}
+		lists[key.Value] = list
+		return nil
+	default:
+		if value.Kind != yaml.ScalarNode {
+			return configError(fileName, value, "%s needs to be a string", key.Value)
+		}
+		params[key.Value] = value.Value
+	}
+
+	if err := p.Validate(params[key.Value]); err != nil {
+		return configError(fileName, value, "%s", err)
+	}
+
+	return nil
+}
+
+func readConfigImportMappings(fileName string, value *yaml.Node, params map[string]string) error {
+	if value.Kind != yaml.MappingNode {
+		return configError(fileName, value, "%s needs to be a mapping from the proto paths to the import specifiers", configImportMappings)
+	}
+
+	for i := 0; i < len(value.Content); i += 2 {
+		protoPath, specifier := value.Content[i], value.Content[i+1]
+		if specifier.Kind != yaml.ScalarNode {
+			return configError(fileName, specifier, "the import specifier of %s needs to be a string", protoPath.Value)
+		}
+		params[ImportMappingPrefix+protoPath.Value] = specifier.Value
+	}
+
+	return nil
+}
+
+func readConfigOverrides(fileName string, value *yaml.Node) ([]*Override, error) {
+	if value.Kind != yaml.SequenceNode {
+		return nil, configError(fileName, value, "%s needs to be a list", configOverrides)
+	}
+
+	overrides := make([]*Override, 0, len(value.Content))
+	for _, node := range value.Content {
+		if node.Kind != yaml.MappingNode {
+			return nil, configError(fileName, node, "the override needs to be a mapping of the parameters")
+		}
+
+		override := &Override{Params: make(map[string]string)}
+		for i := 0; i < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			switch key.Value {
+			case configOverridePackage:
+				override.Package = value.Value
+			case configOverrideFile:
+				override.File = value.Value
+			default:
+				err := readConfigParam(fileName, key, value, override.Params, nil)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if err := validateOverride(override); err != nil {
+			return nil, configError(fileName, node, "%s", err)
+		}
+
+		overrides = append(overrides, override)
+	}
+
+	return overrides, nil
+}
+
+// validateOverride checks the override applies to either a package or a file, and only overrides the parameters
+// which can be overridden with valid values, so that the errors are reported before any file is generated
+func validateOverride(o *Override) error {
+	if (o.Package == "") == (o.File == "") {
+		return errors.Errorf("the override needs either a %s or a %s", configOverridePackage, configOverrideFile)
+	}
+
+	target := configOverrideFile + " " + o.File
+	if o.Package != "" {
+		target = configOverridePackage + " " + o.Package
+	}
+
+	if err := (&Registry{}).setFileParams(o.Params); err != nil {
+		return errors.Wrapf(err, "invalid override of %s", target)
+	}
+
+	return nil
+}
+
+func isOverridableParam(name string) bool {
+	for _, p := range overridableParams {
+		if p == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func configError(fileName string, node *yaml.Node, format string, args ...interface{}) error {
+	return errors.Errorf("%s:%d:%d: %s", fileName, node.Line, node.Column, errors.Errorf(format, args...))
+}
+
+// ForFile returns the registry with the overrides in the config file matching the file applied, the overrides of
+// the package are applied before the ones of the file. the registry itself is returned if there are no overrides
+func (r *Registry) ForFile(fileName, packageName string) *Registry {
+	if len(r.Overrides) == 0 {
+		return r
+	}
+
+	overrides := make([]*Override, 0)
+	for _, o := range r.Overrides {
+		if (o.Package != "" && o.Package == packageName) || (o.File != "" && o.File == fileName) {
+			overrides = append(overrides, o)
+		}
+	}
+	sort.SliceStable(overrides, func(i, j int) bool {
+		return overrides[i].Package != "" && overrides[j].File != ""
+	})
+
+	fileRegistry := *r
+	for _, o := range overrides {
+		// the overrides have been validated when the config file was loaded
+		if err := fileRegistry.setFileParams(o.Params); err != nil {
+			log.Warnf("error applying the override to %s: %v", fileName, err)
+		}
+	}
+
+	return &fileRegistry
+}
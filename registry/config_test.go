@@ -0,0 +1,240 @@
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeConfigFile writes the config file into a temporary directory removed at the end of the test
+func writeConfigFile(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "config")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	fileName := filepath.Join(dir, "ts.yaml")
+	if !assert.NoError(t, ioutil.WriteFile(fileName, []byte(content), 0600)) {
+		t.FailNow()
+	}
+
+	return fileName
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	fileName := writeConfigFile(t, `
+service_style: functions
+field_mask_depth: 1
+overrides:
+  - file: foo/a.proto
+    field_mask_depth: 3
+  - package: foo
+    field_mask_depth: 2
+    service_style: functions_and_class
+`)
+
+	params, err := LoadConfigFile(map[string]string{
+		ConfigFile:     fileName,
+		ServiceStyle:   ServiceStyleClass,
+		FieldMaskDepth: "5",
+		UseProtoNames:  "true",
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	r, err := NewRegistry(params)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	tests := []struct {
+		name                   string
+		fileName               string
+		packageName            string
+		expectedServiceStyle   string
+		expectedFieldMaskDepth int
+	}{
+		{name: "global over flag", fileName: "bar/a.proto", packageName: "bar", expectedServiceStyle: ServiceStyleFunctions, expectedFieldMaskDepth: 1},
+		{name: "package over global", fileName: "foo/b.proto", packageName: "foo", expectedServiceStyle: ServiceStyleFunctionsAndClass, expectedFieldMaskDepth: 2},
+		{name: "file over package", fileName: "foo/a.proto", packageName: "foo", expectedServiceStyle: ServiceStyleFunctionsAndClass, expectedFieldMaskDepth: 3},
+		{name: "file in another package", fileName: "foo/a.proto", packageName: "bar", expectedServiceStyle: ServiceStyleFunctions, expectedFieldMaskDepth: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileRegistry := r.ForFile(tt.fileName, tt.packageName)
+			assert.Equal(t, tt.expectedServiceStyle, fileRegistry.ServiceStyle)
+			assert.Equal(t, tt.expectedFieldMaskDepth, fileRegistry.FieldMaskDepth)
+			// the flags not in the config file are kept
+			assert.True(t, fileRegistry.UseProtoNames)
+		})
+	}
+
+	// the overrides don't leak into the registry they are applied from
+	assert.Equal(t, ServiceStyleFunctions, r.ServiceStyle)
+	assert.Equal(t, 1, r.FieldMaskDepth)
+}
+
+func TestConfigLists(t *testing.T) {
+	fileName := writeConfigFile(t, `
+ts_import_roots:
+  - protos
+  - third_party;vendored
+ts_import_root_aliases: protos
+`)
+
+	params, err := LoadConfigFile(map[string]string{
+		ConfigFile:            fileName,
+		TSImportRootParamsKey: "a;b",
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	// the lists are not turned into parameters, and the flag they override is left out
+	assert.NotContains(t, params, TSImportRootParamsKey)
+	assert.NotContains(t, params, TSImportRootAliasParamsKey)
+
+	opts, err := ParseOptions(params)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{"protos", "third_party;vendored"}, opts.TSImportRoots)
+	assert.Equal(t, []string{"protos"}, opts.TSImportRootAliases)
+}
+
+func TestConfigOverridesRejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{
+			name:        "unsupported key of a file",
+			content:     "overrides:\n  - file: foo/a.proto\n    target: js\n",
+			expectedErr: ":2:5: invalid override of file foo/a.proto: target cannot be overridden per package or file, it needs to be one of use_proto_names, field_mask_depth, service_style",
+		},
+		{
+			name:        "unsupported key of a package",
+			content:     "overrides:\n  - paths: import\n    package: foo\n",
+			expectedErr: ":2:5: invalid override of package foo: paths cannot be overridden per package or file, it needs to be one of use_proto_names, field_mask_depth, service_style",
+		},
+		{
+			name:        "unknown key",
+			content:     "overrides:\n  - file: foo/a.proto\n    use_proto_name: true\n",
+			expectedErr: ":3:5: unknown parameter use_proto_name, did you mean use_proto_names?",
+		},
+		{
+			name:        "invalid value",
+			content:     "overrides:\n  - file: foo/a.proto\n    field_mask_depth: -1\n",
+			expectedErr: ":2:5: invalid override of file foo/a.proto: invalid field_mask_depth -1, it needs to be a non negative integer",
+		},
+		{
+			name:        "list",
+			content:     "overrides:\n  - file: foo/a.proto\n    ts_import_roots: [protos]\n",
+			expectedErr: ":3:5: ts_import_roots cannot be overridden per package or file, it needs to be one of use_proto_names, field_mask_depth, service_style",
+		},
+		{
+			name:        "no file nor package",
+			content:     "overrides:\n  - service_style: functions\n",
+			expectedErr: ":2:5: the override needs either a package or a file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := writeConfigFile(t, tt.content)
+			_, err := ParseOptions(map[string]string{ConfigFile: fileName})
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), fileName+tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestOptionsOverridesRejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		override    *Override
+		expectedErr string
+	}{
+		{
+			name:        "unsupported key",
+			override:    &Override{File: "foo/a.proto", Params: map[string]string{UseProtoNames: "true", Module: "foo"}},
+			expectedErr: "invalid override of file foo/a.proto: module cannot be overridden per package or file, it needs to be one of use_proto_names, field_mask_depth, service_style",
+		},
+		{
+			name:        "invalid value",
+			override:    &Override{Package: "foo", Params: map[string]string{ServiceStyle: "object"}},
+			expectedErr: "invalid override of package foo: invalid service_style object, it needs to be one of class, functions, functions_and_class",
+		},
+		{
+			name:        "file and package",
+			override:    &Override{Package: "foo", File: "foo/a.proto", Params: map[string]string{}},
+			expectedErr: "the override needs either a package or a file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Overrides = []*Override{tt.override}
+			_, err := NewRegistryFromOptions(opts)
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.expectedErr, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseFieldMaskDepth(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    int
+		expectedErr bool
+	}{
+		{value: "0", expected: 0},
+		{value: "3", expected: 3},
+		{value: "-1", expectedErr: true},
+		{value: "deep", expectedErr: true},
+		{value: "", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			fieldMaskDepth, err := parseFieldMaskDepth(tt.value)
+			if tt.expectedErr {
+				if assert.Error(t, err) {
+					assert.Equal(t, "invalid field_mask_depth "+tt.value+", it needs to be a non negative integer", err.Error())
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, fieldMaskDepth)
+		})
+	}
+
+	_, err := ParseOptions(map[string]string{FieldMaskDepth: "-1"})
+	assert.EqualError(t, err, "invalid field_mask_depth -1, it needs to be a non negative integer")
+}
+
+func TestHermeticFetchModuleDirectory(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ImportResolution = ImportResolutionHermetic
+	opts.FetchModuleDirectory = "/tmp/lib"
+	_, err := NewRegistryFromOptions(opts)
+	assert.EqualError(t, err, "fetch_module_directory needs to be relative to the output directory with import_resolution hermetic")
+
+	opts.FetchModuleDirectory = "lib"
+	r, err := NewRegistryFromOptions(opts)
+	if assert.NoError(t, err) {
+		assert.Equal(t, ImportResolutionHermetic, r.ImportResolution)
+	}
+}
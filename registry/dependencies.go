@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"sort"
+
+	log "github.com/sirupsen/logrus" // nolint: depguard
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+// wellKnownTypesPackage is the package of the well-known types, which are never generated as dependencies
+const wellKnownTypesPackage = "google.protobuf"
+
+// collectDependenciesToGenerate walks through the types referenced from the files to generate transitively, and marks
+// the files defining them to be generated as well. files mapped to an import specifier with ts_package or the import
+// mapping parameters are left out, as they are expected to be provided by the packages they are mapped to. so are the
+// well-known types, which are shared by every proto tree and are to be mapped or generated on their own
+func (r *Registry) collectDependenciesToGenerate(filesData map[string]*data.File) {
+	r.DependenciesToGenerate = make(map[string]bool)
+	queue := make([]string, 0, len(r.FilesToGenerate))
+	for name := range r.FilesToGenerate {
+		queue = append(queue, name)
+	}
+	sort.Strings(queue)
+
+	for len(queue) > 0 {
+		fileData, ok := filesData[queue[0]]
+		queue = queue[1:]
+		if !ok {
+			continue
+		}
+
+		for _, outputFile := range fileData.OutputFiles() {
+			for _, typeName := range outputFile.ExternalDependingTypes {
+				typeInfo, ok := r.Types[typeName]
+				if !ok || r.IsFileToGenerate(typeInfo.File) || r.DependenciesToGenerate[typeInfo.File] {
+					continue
+				}
+
+				if typeInfo.Package == wellKnownTypesPackage {
+					log.Debugf("dependency %s holds well-known types, skipping", typeInfo.File)
+					continue
+				}
+
+				if pkg, mapped := r.getTSPackage(typeInfo.File); mapped {
+					log.Debugf("dependency %s is mapped to %s, skipping", typeInfo.File, pkg)
+					continue
+				}
+
+				log.Debugf("dependency %s of %s will be generated", typeInfo.File, fileData.Name)
+				r.DependenciesToGenerate[typeInfo.File] = true
+				queue = append(queue, typeInfo.File)
+			}
+		}
+	}
+}
+
+// IsDependencyToGenerate returns whether the file is not in the request to generate, but will be generated
+// as the files to generate depend on it with generate_dependencies turned on
+func (r *Registry) IsDependencyToGenerate(name string) bool {
+	return r.DependenciesToGenerate[name]
+}
@@ -0,0 +1,60 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectDependenciesToGenerate(t *testing.T) {
+	tests := []struct {
+		name                 string
+		generateDependencies bool
+		importMappings       map[string]string
+		expected             map[string]bool
+		expectedIssues       []string
+	}{
+		{name: "files to generate only", expected: map[string]bool{}, expectedIssues: []string{}},
+		{
+			name:                 "dependencies without the well-known types",
+			generateDependencies: true,
+			expected:             map[string]bool{"lib/lib.proto": true},
+			expectedIssues:       []string{"lib/lib.proto:4:1: lib.Book: TypeScript identifier BaseBook collides with lib.BaseBook"},
+		},
+		{
+			name:                 "mapped dependencies",
+			generateDependencies: true,
+			importMappings:       map[string]string{"lib/lib.proto": "@org/lib"},
+			expected:             map[string]bool{},
+			expectedIssues:       []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				opts.GenerateDependencies = tt.generateDependencies
+				opts.ImportMappings = tt.importMappings
+			})
+
+			filesData, err := analyseFixture(t, r, "dependencies.textpb", "app/app.proto")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			dependencies := make(map[string]bool)
+			for name := range filesData {
+				if r.IsDependencyToGenerate(name) {
+					dependencies[name] = true
+				}
+			}
+			assert.Equal(t, tt.expected, dependencies)
+
+			issues := make([]string, 0)
+			for _, issue := range r.Lint(filesData) {
+				issues = append(issues, issue.String())
+			}
+			assert.Equal(t, tt.expectedIssues, issues)
+		})
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
 )
 
-func (r *Registry) analyseEnumType(fileData *data.File, packageName, fileName string, parents []string, enum *descriptorpb.EnumDescriptorProto) {
+func (r *Registry) analyseEnumType(fileData *data.File, packageName, fileName string, parents []string, path []int32, enum *descriptorpb.EnumDescriptorProto) {
 	packageIdentifier := r.getNameOfPackageLevelIdentifier(parents, enum.GetName())
 	fqName := r.getFullQualifiedName(packageName, parents, enum.GetName())
 	protoType := descriptorpb.FieldDescriptorProto_TYPE_ENUM
@@ -20,7 +20,9 @@ func (r *Registry) analyseEnumType(fileData *data.File, packageName, fileName st
 	}
 
 	enumData := data.NewEnum()
-	enumData.Name = packageIdentifier
+	enumData.Name = r.getLocalName(packageIdentifier, enum.GetName())
+	enumData.Namespace = r.getNamespace(parents)
+	enumData.Location = newLocation(fileName, fqName, path)
 
 	for _, e := range enum.GetValue() {
 		enumData.Values = append(enumData.Values, e.GetName())
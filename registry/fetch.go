@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+// bytesType is the proto type of bytes fields, which are encoded with base64 in JSON
+const bytesType = "bytes"
+
+// AnalyseFetchModule works out the features of the fetch module used by the files to be generated, or turns all of
+// them on if fetch_module_features is all
+func (r *Registry) AnalyseFetchModule(filesData map[string]*data.File) *data.FetchModule {
+	if r.FetchModuleFeatures == FetchModuleFeaturesAll {
+		return &data.FetchModule{
+			NeedsBase64:               true,
+			NeedsServerStreaming:      true,
+			NeedsURLSearchParams:      true,
+			NeedsLongRunningOperation: true,
+			NeedsFieldMask:            true,
+		}
+	}
+
+	fetchModule := &data.FetchModule{}
+	for _, fileData := range filesData {
+		if !r.IsFileToGenerate(fileData.Name) && !r.IsDependencyToGenerate(fileData.Name) {
+			continue
+		}
+
+		fetchModule.NeedsBase64 = fetchModule.NeedsBase64 || r.hasBytesFields(fileData.Messages, make(map[string]bool))
+
+		fieldMaskDepth := r.ForFile(fileData.Name, fileData.Package).FieldMaskDepth
+		for _, message := range fileData.Messages {
+			fetchModule.NeedsFieldMask = fetchModule.NeedsFieldMask || (fieldMaskDepth > 0 && len(message.Fields) > 0)
+		}
+
+		for _, outputFile := range fileData.OutputFiles() {
+			for _, service := range outputFile.Services {
+				for _, method := range service.Methods {
+					fetchModule.NeedsServerStreaming = fetchModule.NeedsServerStreaming || method.ServerStreaming
+					fetchModule.NeedsURLSearchParams = fetchModule.NeedsURLSearchParams || method.HTTPMethod == "GET"
+					fetchModule.NeedsLongRunningOperation = fetchModule.NeedsLongRunningOperation || method.LongRunning != nil
+				}
+			}
+		}
+	}
+
+	return fetchModule
+}
+
+// hasBytesFields returns whether any of the messages or the messages referenced from them has bytes fields,
+// as the payloads carry the referenced messages from other files as well
+func (r *Registry) hasBytesFields(messages []*data.Message, visited map[string]bool) bool {
+	for _, message := range messages {
+		if visited[message.FQType] {
+			continue
+		}
+		visited[message.FQType] = true
+
+		for _, field := range message.Fields {
+			if r.isBytesType(field.Type, visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (r *Registry) isBytesType(fieldType string, visited map[string]bool) bool {
+	if fieldType == bytesType {
+		return true
+	}
+
+	typeInfo, ok := r.Types[fieldType]
+	if !ok {
+		return false
+	}
+
+	if typeInfo.IsMapEntry {
+		return typeInfo.ValueType != nil && r.isBytesType(typeInfo.ValueType.Type, visited)
+	}
+
+	return typeInfo.Message != nil && r.hasBytesFields([]*data.Message{typeInfo.Message}, visited)
+}
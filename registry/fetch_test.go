@@ -0,0 +1,67 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+func TestAnalyseFetchModule(t *testing.T) {
+	tests := []struct {
+		name            string
+		fixture         string
+		filesToGenerate []string
+		change          func(opts *Options)
+		expected        *data.FetchModule
+	}{
+		{
+			name:     "all features by default",
+			fixture:  "exclude.textpb",
+			expected: &data.FetchModule{NeedsBase64: true, NeedsServerStreaming: true, NeedsURLSearchParams: true, NeedsLongRunningOperation: true, NeedsFieldMask: true},
+		},
+		{
+			name:     "query string",
+			fixture:  "exclude.textpb",
+			change:   func(opts *Options) { opts.FetchModuleFeatures = FetchModuleFeaturesUsed },
+			expected: &data.FetchModule{NeedsURLSearchParams: true},
+		},
+		{
+			name:     "field mask",
+			fixture:  "exclude.textpb",
+			change:   func(opts *Options) { opts.FetchModuleFeatures = FetchModuleFeaturesUsed; opts.FieldMaskDepth = 1 },
+			expected: &data.FetchModule{NeedsURLSearchParams: true, NeedsFieldMask: true},
+		},
+		{
+			name:     "long-running operations",
+			fixture:  "long_running.textpb",
+			change:   func(opts *Options) { opts.FetchModuleFeatures = FetchModuleFeaturesUsed },
+			expected: &data.FetchModule{NeedsURLSearchParams: true, NeedsLongRunningOperation: true},
+		},
+		{
+			name:            "server streaming and bytes fields referenced from another file",
+			fixture:         "fetch.textpb",
+			filesToGenerate: []string{"media/media.proto"},
+			change:          func(opts *Options) { opts.FetchModuleFeatures = FetchModuleFeaturesUsed },
+			expected:        &data.FetchModule{NeedsBase64: true, NeedsServerStreaming: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				if tt.change != nil {
+					tt.change(opts)
+				}
+			})
+
+			filesData, err := analyseFixture(t, r, tt.fixture, tt.filesToGenerate...)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			assert.Equal(t, tt.expected, r.AnalyseFetchModule(filesData))
+		})
+	}
+}
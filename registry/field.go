@@ -49,17 +49,19 @@ func (r *Registry) getFieldType(f *descriptorpb.FieldDescriptorProto) string {
 	return typeName
 }
 
-func (r *Registry) analyseField(fileData *data.File, msgData *data.Message, packageName string, f *descriptorpb.FieldDescriptorProto) {
+func (r *Registry) analyseField(fileData *data.File, msgData *data.Message, packageName string, location *data.Location, f *descriptorpb.FieldDescriptorProto) {
 	fqTypeName := r.getFieldType(f)
 
 	isExternal := r.isExternalDependenciesOutsidePackage(fqTypeName, packageName)
 
 	fieldData := &data.Field{
 		Name:         f.GetName(),
+		JSONName:     f.GetJsonName(),
 		Type:         fqTypeName,
 		IsExternal:   isExternal,
 		IsOneOfField: f.OneofIndex != nil,
 		Message:      msgData,
+		Location:     location,
 	}
 
 	if f.Label != nil {
@@ -19,30 +19,55 @@ func (r *Registry) analyseFile(f *descriptorpb.FileDescriptorProto) (*data.File,
 	packageName := f.GetPackage()
 	parents := make([]string, 0)
 	fileData.Name = fileName
-	fileData.TSFileName = data.GetTSFileName(fileName)
+	fileData.Package = packageName
+	tsFileName, err := r.getTSFileName(fileName, packageName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting the generated file name")
+	}
+	fileData.TSFileName = tsFileName
 	if proto.HasExtension(f.Options, options.E_TsPackage) {
-		r.TSPackages[fileData.TSFileName] = proto.GetExtension(f.Options, options.E_TsPackage).(string)
+		if _, ok := r.TSPackages[data.GetTSFileName(fileName)]; ok {
+			// the import mapping parameters take precedence over the option, the same as protoc-gen-go does for go_package
+			log.Debugf("ts_package of %s has been overridden by the import mapping parameter", fileName)
+		} else {
+			r.TSPackages[data.GetTSFileName(fileName)] = proto.GetExtension(f.Options, options.E_TsPackage).(string)
+		}
 	}
 
 	// analyse enums
-	for _, enum := range f.EnumType {
-		r.analyseEnumType(fileData, packageName, fileName, parents, enum)
+	for i, enum := range f.EnumType {
+		r.analyseEnumType(fileData, packageName, fileName, parents, childPath(nil, fileEnumTypePath, i), enum)
 	}
 
 	// analyse messages, each message will go recursively
-	for _, message := range f.MessageType {
-		r.analyseMessage(fileData, packageName, fileName, parents, message)
+	for i, message := range f.MessageType {
+		r.analyseMessage(fileData, packageName, fileName, parents, childPath(nil, fileMessageTypePath, i), message)
 	}
 
-	// analyse services
-	for _, service := range f.Service {
-		r.analyseService(fileData, packageName, fileName, service)
+	// analyse services, they go into a separate client file if client_file_suffix is set
+	serviceFileData := fileData
+	if r.ClientFileSuffix != "" {
+		serviceFileData = r.newClientFile(fileData)
 	}
 
-	// add fetch module after analysed all services in the file. will add dependencies if there is any
-	err := r.addFetchModuleDependencies(fileData)
-	if err != nil {
-		return nil, errors.Wrapf(err, "error adding fetch module for file %s", fileData.Name)
+	for i, service := range f.Service {
+		err := r.analyseService(serviceFileData, packageName, fileName, childPath(nil, fileServicePath, i), service)
+		if err != nil {
+			return nil, errors.Wrapf(locateElementError(f, err), "error analysing service %s", service.GetName())
+		}
+	}
+
+	if serviceFileData != fileData && len(serviceFileData.Services) > 0 {
+		fileData.Client = serviceFileData
+		r.analyseClientFileTypeDependencies(serviceFileData)
+	}
+
+	for _, outputFile := range fileData.OutputFiles() {
+		// add fetch module after analysed all services in the file. will add dependencies if there is any
+		err = r.addFetchModuleDependencies(outputFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error adding fetch module for file %s", outputFile.TSFileName)
+		}
 	}
 
 	r.analyseFilePackageTypeDependencies(fileData)
@@ -56,6 +81,34 @@ func (r *Registry) addFetchModuleDependencies(fileData *data.File) error {
 		return nil
 	}
 
+	if r.FetchModulePackage != "" {
+		log.Debugf("added fetch dependency %s for %s", r.FetchModulePackage, fileData.TSFileName)
+		fileData.Dependencies = append(fileData.Dependencies, &data.Dependency{
+			ModuleIdentifier: fetchModuleIdentifier,
+			SourceFile:       r.FetchModulePackage,
+			Runtime:          true,
+		})
+		fileData.FetchModuleAPIVersion = FetchModuleAPIVersion
+
+		return nil
+	}
+
+	if r.ImportResolution == ImportResolutionHermetic {
+		sourceFile, err := r.getRelativeImport(fileData.TSFileName, r.GetFetchModulePath())
+		if err != nil {
+			return errors.Wrapf(err, "error getting source file for fetch module")
+		}
+
+		log.Debugf("added fetch dependency %s for %s", sourceFile, fileData.TSFileName)
+		fileData.Dependencies = append(fileData.Dependencies, &data.Dependency{
+			ModuleIdentifier: fetchModuleIdentifier,
+			SourceFile:       sourceFile,
+			Runtime:          true,
+		})
+
+		return nil
+	}
+
 	absDir, err := filepath.Abs(r.FetchModuleDirectory)
 	if err != nil {
 		return errors.Wrapf(err, "error looking up absolute path for fetch module directory %s", r.FetchModuleDirectory)
@@ -69,7 +122,7 @@ func (r *Registry) addFetchModuleDependencies(fileData *data.File) error {
 		return errors.Wrapf(err, "error looking up root alias for fetch module directory %s", r.FetchModuleDirectory)
 	}
 
-	fileName := filepath.Join(r.FetchModuleDirectory, r.FetchModuleFilename)
+	fileName := r.GetFetchModulePath()
 
 	sourceFile, err := r.getSourceFileForImport(fileData.TSFileName, fileName, foundAtRoot, alias)
 	if err != nil {
@@ -78,8 +131,9 @@ func (r *Registry) addFetchModuleDependencies(fileData *data.File) error {
 
 	log.Debugf("added fetch dependency %s for %s", sourceFile, fileData.TSFileName)
 	fileData.Dependencies = append(fileData.Dependencies, &data.Dependency{
-		ModuleIdentifier: "fm",
+		ModuleIdentifier: fetchModuleIdentifier,
 		SourceFile:       sourceFile,
+		Runtime:          true,
 	})
 
 	return nil
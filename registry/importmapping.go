@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+// getImportMappings collects the import specifiers keyed by the proto paths of the M<proto path>=<import specifier>
+// parameters. mappings keyed by a proto file are returned keyed by the TS file name like the ones from the ts_package
+// option, mappings keyed by a directory ending with / apply to all the files under it and are returned separately
+func getImportMappings(mappings map[string]string) (packages, prefixes map[string]string, err error) {
+	packages = make(map[string]string)
+	prefixes = make(map[string]string)
+	for protoPath, specifier := range mappings {
+		key := ImportMappingPrefix + protoPath
+		if specifier == "" {
+			return nil, nil, errors.Errorf("missing import specifier for import mapping %s", key)
+		}
+
+		switch {
+		case strings.HasSuffix(protoPath, "/"):
+			prefixes[protoPath] = specifier
+		case strings.HasSuffix(protoPath, ".proto"):
+			packages[data.GetTSFileName(protoPath)] = specifier
+		default:
+			return nil, nil, errors.Errorf("invalid import mapping %s, it needs to be either a proto file or a directory ending with /", key)
+		}
+	}
+
+	return packages, prefixes, nil
+}
+
+// getTSPackage returns the import specifier the proto file has been mapped to, either by the ts_package option or
+// the import mapping parameters. exact mappings take precedence over the directory mappings, of which the longest wins
+func (r *Registry) getTSPackage(fileName string) (string, bool) {
+	if pkg, ok := r.TSPackages[data.GetTSFileName(fileName)]; ok {
+		return pkg, true
+	}
+
+	prefixes := make([]string, 0, len(r.TSPackagePrefixes))
+	for prefix := range r.TSPackagePrefixes {
+		if strings.HasPrefix(fileName, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	if len(prefixes) == 0 {
+		return "", false
+	}
+
+	sort.Slice(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+
+	prefix := prefixes[0]
+	rest := strings.TrimSuffix(strings.TrimPrefix(fileName, prefix), ".proto") + strings.TrimSuffix(r.FileSuffix, ".ts") + r.ImportExtension
+
+	return strings.TrimSuffix(r.TSPackagePrefixes[prefix], "/") + "/" + rest, true
+}
+
+// getRelativeImport returns the import specifier of the target from the source with the import extension, both being
+// paths relative to the output directory. it's worked out lexically from the paths alone, so that it doesn't depend on the current directory
+func (r *Registry) getRelativeImport(source, target string) (string, error) {
+	rel, err := filepath.Rel(filepath.Dir(source), target)
+	if err != nil {
+		return "", errors.Wrapf(err, "error looking up relative path from %s to %s", source, target)
+	}
+
+	specifier := filepath.ToSlash(rel)
+	if !strings.HasPrefix(specifier, "../") {
+		specifier = "./" + specifier
+	}
+
+	return strings.TrimSuffix(specifier, ".ts") + r.ImportExtension, nil
+}
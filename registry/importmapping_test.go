@@ -0,0 +1,137 @@
+package registry
+
+import (
+	"testing"
+
+	descriptorpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/options"
+)
+
+func TestGetImportMappings(t *testing.T) {
+	tests := []struct {
+		name             string
+		mappings         map[string]string
+		expectedPackages map[string]string
+		expectedPrefixes map[string]string
+		expectedErr      string
+	}{
+		{name: "no mappings", mappings: map[string]string{}, expectedPackages: map[string]string{}, expectedPrefixes: map[string]string{}},
+		{
+			name:             "file and directory",
+			mappings:         map[string]string{"google/type/date.proto": "@org/date", "google/api/": "@org/api"},
+			expectedPackages: map[string]string{"google/type/date.pb.ts": "@org/date"},
+			expectedPrefixes: map[string]string{"google/api/": "@org/api"},
+		},
+		{name: "missing specifier", mappings: map[string]string{"foo.proto": ""}, expectedErr: "missing import specifier for import mapping Mfoo.proto"},
+		{
+			name:        "neither file nor directory",
+			mappings:    map[string]string{"google/type": "@org/type"},
+			expectedErr: "invalid import mapping Mgoogle/type, it needs to be either a proto file or a directory ending with /",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packages, prefixes, err := getImportMappings(tt.mappings)
+			if tt.expectedErr != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.expectedErr, err.Error())
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedPackages, packages)
+			assert.Equal(t, tt.expectedPrefixes, prefixes)
+		})
+	}
+}
+
+func TestGetTSPackage(t *testing.T) {
+	mappings := map[string]string{
+		"google/type/date.proto":     "@org/date",
+		"google/type/datetime.proto": "@org/datetime",
+		"google/":                    "@org/google/",
+		"google/type/":               "@org/type",
+	}
+	tsPackages := map[string]string{
+		"google/type/date.proto":  "@option/date",
+		"google/type/money.proto": "@option/money",
+	}
+
+	tests := []struct {
+		name            string
+		fileSuffix      string
+		importExtension string
+		fileName        string
+		expected        string
+	}{
+		{name: "file mapping over ts_package", fileName: "google/type/date.proto", expected: "@org/date"},
+		{name: "file mapping", fileName: "google/type/datetime.proto", expected: "@org/datetime"},
+		{name: "ts_package over directory mapping", fileName: "google/type/money.proto", expected: "@option/money"},
+		{name: "longest directory mapping", fileName: "google/type/latlng.proto", expected: "@org/type/latlng.pb"},
+		{name: "directory mapping", fileName: "google/api/http.proto", expected: "@org/google/api/http.pb"},
+		{name: "directory mapping with file suffix", fileSuffix: ".gen.ts", fileName: "google/api/http.proto", expected: "@org/google/api/http.gen"},
+		{name: "directory mapping with import extension", importExtension: ".js", fileName: "google/api/http.proto", expected: "@org/google/api/http.pb.js"},
+		{name: "file mapping without import extension", importExtension: ".js", fileName: "google/type/datetime.proto", expected: "@org/datetime"},
+		{name: "not mapped", fileName: "foo/bar.proto"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				opts.ImportMappings = mappings
+				if tt.fileSuffix != "" {
+					opts.FileSuffix = tt.fileSuffix
+				}
+				opts.ImportExtension = tt.importExtension
+			})
+
+			for fileName, tsPackage := range tsPackages {
+				fileOptions := &descriptorpb.FileOptions{}
+				proto.SetExtension(fileOptions, options.E_TsPackage, tsPackage)
+				_, err := r.analyseFile(&descriptorpb.FileDescriptorProto{
+					Name:    proto.String(fileName),
+					Package: proto.String("google.type"),
+					Options: fileOptions,
+				})
+				assert.NoError(t, err)
+			}
+
+			pkg, ok := r.getTSPackage(tt.fileName)
+			assert.Equal(t, tt.expected != "", ok)
+			assert.Equal(t, tt.expected, pkg)
+		})
+	}
+}
+
+func TestGetRelativeImport(t *testing.T) {
+	tests := []struct {
+		name            string
+		importExtension string
+		source          string
+		target          string
+		expected        string
+	}{
+		{name: "same directory", source: "foo/a.pb.ts", target: "foo/b.pb.ts", expected: "./b.pb"},
+		{name: "sub directory", source: "a.pb.ts", target: "foo/bar/b.pb.ts", expected: "./foo/bar/b.pb"},
+		{name: "parent directory", source: "foo/bar/a.pb.ts", target: "b.pb.ts", expected: "../../b.pb"},
+		{name: "sibling directory", source: "foo/a.pb.ts", target: "bar/b.pb.ts", expected: "../bar/b.pb"},
+		{name: "import extension", importExtension: ".js", source: "foo/a.pb.ts", target: "fetch.pb.ts", expected: "../fetch.pb.js"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				opts.ImportExtension = tt.importExtension
+			})
+
+			specifier, err := r.getRelativeImport(tt.source, tt.target)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, specifier)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package registry
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+// AnalyseIndexFiles groups the files to generate and the dependencies generated along with them by their packages
+// and returns the index files re-exporting each of them as a namespace, along with their client files. the index
+// files are ordered by package and the exports by file name
+func (r *Registry) AnalyseIndexFiles(filesData map[string]*data.File) ([]*data.IndexFile, error) {
+	filesByPackage := make(map[string][]*data.File)
+	for _, fileData := range filesData {
+		if !r.IsFileToGenerate(fileData.Name) && !r.IsDependencyToGenerate(fileData.Name) {
+			continue
+		}
+
+		filesByPackage[fileData.Package] = append(filesByPackage[fileData.Package], fileData)
+	}
+
+	packages := make([]string, 0, len(filesByPackage))
+	for packageName := range filesByPackage {
+		packages = append(packages, packageName)
+	}
+	sort.Strings(packages)
+
+	indexFiles := make([]*data.IndexFile, 0, len(packages))
+	for _, packageName := range packages {
+		indexFile := &data.IndexFile{
+			Package: packageName,
+			Name:    r.getIndexFileName(packageName),
+			Exports: make([]*data.Dependency, 0, len(filesByPackage[packageName])),
+		}
+
+		files := filesByPackage[packageName]
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].Name < files[j].Name
+		})
+
+		taken := make(map[string]bool)
+		for _, fileData := range files {
+			for _, outputFile := range fileData.OutputFiles() {
+				sourceFile, err := r.getRelativeImport(indexFile.Name, outputFile.TSFileName)
+				if err != nil {
+					return nil, errors.Wrapf(err, "error getting the export of %s for the index file of %s", outputFile.TSFileName, packageName)
+				}
+
+				candidate := r.getModuleIdentifierCandidate(packageName, fileData.Name)
+				if outputFile != fileData {
+					// the client file is exported next to the types file of the same proto
+					candidate += "Client"
+				}
+				identifier := candidate
+				for i := 2; taken[identifier]; i++ {
+					identifier = candidate + strconv.Itoa(i)
+				}
+				taken[identifier] = true
+
+				indexFile.Exports = append(indexFile.Exports, &data.Dependency{
+					ModuleIdentifier: identifier,
+					SourceFile:       sourceFile,
+				})
+			}
+		}
+
+		indexFiles = append(indexFiles, indexFile)
+	}
+
+	return indexFiles, nil
+}
@@ -0,0 +1,57 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+func TestAnalyseIndexFiles(t *testing.T) {
+	tests := []struct {
+		name                 string
+		generateDependencies bool
+		expected             []*data.IndexFile
+	}{
+		{
+			name: "files to generate",
+			expected: []*data.IndexFile{
+				{Package: "shop.v1", Name: "shop/v1/index.ts", Exports: []*data.Dependency{
+					{ModuleIdentifier: "ShopV1Book", SourceFile: "./book.pb"},
+					{ModuleIdentifier: "ShopV1Order", SourceFile: "./order.pb"},
+				}},
+			},
+		},
+		{
+			name:                 "dependencies to generate",
+			generateDependencies: true,
+			expected: []*data.IndexFile{
+				{Package: "common", Name: "common/index.ts", Exports: []*data.Dependency{
+					{ModuleIdentifier: "CommonMoney", SourceFile: "./money.pb"},
+				}},
+				{Package: "shop.v1", Name: "shop/v1/index.ts", Exports: []*data.Dependency{
+					{ModuleIdentifier: "ShopV1Book", SourceFile: "./book.pb"},
+					{ModuleIdentifier: "ShopV1Order", SourceFile: "./order.pb"},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				opts.GenerateDependencies = tt.generateDependencies
+			})
+
+			filesData, err := analyseFixture(t, r, "layout.textpb", "shop/v1/book.proto", "shop/v1/order.proto")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			indexFiles, err := r.AnalyseIndexFiles(filesData)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, indexFiles)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+// indexFileName is the name of the index files generated for the packages
+const indexFileName = "index.ts"
+
+// getTSFileName returns the name of the file generated for the proto file with the configured output layout.
+// it's an error for the files to generate not to start with the module prefix. other files are placed relatively to
+// the module directory, e.g. ../other/baz.pb.ts, so that the imports of them resolve from the output directory, those
+// that would be generated outside of it are refused by checkOutputFiles
+func (r *Registry) getTSFileName(fileName, packageName string) (string, error) {
+	dir := path.Dir(filepath.ToSlash(fileName))
+	if r.Paths == PathsImport {
+		dir = strings.ReplaceAll(packageName, ".", "/")
+	}
+
+	base := filepath.Base(fileName)
+	name := path.Join(dir, strings.TrimSuffix(base, filepath.Ext(base))+r.FileSuffix)
+
+	if r.Module == "" {
+		return name, nil
+	}
+
+	prefix := strings.TrimSuffix(r.Module, "/") + "/"
+	if !strings.HasPrefix(name, prefix) {
+		if !r.IsFileToGenerate(fileName) {
+			rel, err := filepath.Rel(filepath.FromSlash(prefix), filepath.FromSlash(name))
+			if err != nil {
+				return "", errors.Wrapf(err, "error looking up the path of %s relative to the %s prefix %s", name, Module, r.Module)
+			}
+
+			return filepath.ToSlash(rel), nil
+		}
+
+		return "", errors.Errorf("generated file %s for %s does not start with the %s prefix %s", name, fileName, Module, r.Module)
+	}
+
+	return strings.TrimPrefix(name, prefix), nil
+}
+
+// checkOutputFiles makes sure the files to generate and the dependencies to generate are written inside the
+// output directory, and that no two of them are generated into the same file, e.g. with paths=import and
+// files of the same name in a package, which would otherwise overwrite each other silently
+func (r *Registry) checkOutputFiles(filesData map[string]*data.File) error {
+	names := make([]string, 0, len(filesData))
+	for name := range filesData {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	generated := make(map[string]string)
+	for _, name := range names {
+		if !r.IsFileToGenerate(name) && !r.IsDependencyToGenerate(name) {
+			continue
+		}
+
+		for _, outputFile := range filesData[name].OutputFiles() {
+			if strings.HasPrefix(outputFile.TSFileName, "../") {
+				return errors.Errorf("generated file %s for dependency %s does not start with the %s prefix %s",
+					path.Join(r.Module, outputFile.TSFileName), name, Module, r.Module)
+			}
+
+			if previous, ok := generated[outputFile.TSFileName]; ok {
+				return errors.Errorf("both %s and %s are generated into %s, change %s or rename one of them",
+					previous, name, outputFile.TSFileName, Paths)
+			}
+			generated[outputFile.TSFileName] = name
+		}
+	}
+
+	return nil
+}
+
+// getIndexFileName returns the name of the index file of the package, which is placed in the directory named after
+// the package with the module prefix stripped if it has one
+func (r *Registry) getIndexFileName(packageName string) string {
+	name := path.Join(strings.ReplaceAll(packageName, ".", "/"), indexFileName)
+	if r.Module == "" {
+		return name
+	}
+
+	return strings.TrimPrefix(name, strings.TrimSuffix(r.Module, "/")+"/")
+}
+
+// GetFetchModulePath returns the path of the fetch module file with the module prefix stripped if it has one
+func (r *Registry) GetFetchModulePath() string {
+	name := filepath.Join(r.FetchModuleDirectory, r.FetchModuleFilename)
+	if r.Module == "" {
+		return name
+	}
+
+	return strings.TrimPrefix(name, filepath.FromSlash(strings.TrimSuffix(r.Module, "/")+"/"))
+}
+
+// getClientFileName returns the name of the client file the services are generated into, next to the file of the types
+func (r *Registry) getClientFileName(tsFileName string) string {
+	return strings.TrimSuffix(tsFileName, r.FileSuffix) + r.ClientFileSuffix
+}
@@ -0,0 +1,202 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTSFileName(t *testing.T) {
+	tests := []struct {
+		name           string
+		paths          string
+		module         string
+		fileSuffix     string
+		fileToGenerate bool
+		fileName       string
+		packageName    string
+		expected       string
+		expectedErr    string
+	}{
+		{name: "source relative", fileName: "foo/bar/baz.proto", packageName: "foo.bar", expected: "foo/bar/baz.pb.ts"},
+		{name: "source relative ignores package", fileName: "protos/baz.proto", packageName: "foo.bar", expected: "protos/baz.pb.ts"},
+		{name: "import", paths: PathsImport, fileName: "protos/baz.proto", packageName: "foo.bar", expected: "foo/bar/baz.pb.ts"},
+		{name: "import without package", paths: PathsImport, fileName: "protos/baz.proto", expected: "baz.pb.ts"},
+		{name: "file suffix", fileSuffix: ".gen.ts", fileName: "foo/baz.proto", packageName: "foo", expected: "foo/baz.gen.ts"},
+		{name: "module", module: "foo", fileToGenerate: true, fileName: "foo/bar/baz.proto", packageName: "foo.bar", expected: "bar/baz.pb.ts"},
+		{name: "module with trailing slash", module: "foo/", fileToGenerate: true, fileName: "foo/bar/baz.proto", packageName: "foo.bar", expected: "bar/baz.pb.ts"},
+		{name: "module with import", paths: PathsImport, module: "foo", fileToGenerate: true, fileName: "baz.proto", packageName: "foo.bar", expected: "bar/baz.pb.ts"},
+		{name: "module not matching a dependency", module: "foo", fileName: "other/baz.proto", packageName: "other", expected: "../other/baz.pb.ts"},
+		{name: "nested module not matching a dependency", module: "foo/bar", fileName: "other/baz.proto", packageName: "other", expected: "../../other/baz.pb.ts"},
+		{
+			name:           "module not matching a file to generate",
+			module:         "foo",
+			fileToGenerate: true,
+			fileName:       "other/baz.proto",
+			packageName:    "other",
+			expectedErr:    "generated file other/baz.pb.ts for other/baz.proto does not start with the module prefix foo",
+		},
+		{name: "module matching a partial directory", module: "foo", fileToGenerate: true, fileName: "foobar/baz.proto", packageName: "foobar", expectedErr: "generated file foobar/baz.pb.ts for foobar/baz.proto does not start with the module prefix foo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				if tt.paths != "" {
+					opts.Paths = tt.paths
+				}
+				if tt.fileSuffix != "" {
+					opts.FileSuffix = tt.fileSuffix
+				}
+				opts.Module = tt.module
+			})
+			r.FilesToGenerate = map[string]bool{}
+			if tt.fileToGenerate {
+				r.FilesToGenerate[tt.fileName] = true
+			}
+
+			name, err := r.getTSFileName(tt.fileName, tt.packageName)
+			if tt.expectedErr != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.expectedErr, err.Error())
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, name)
+		})
+	}
+}
+
+func TestGetIndexFileName(t *testing.T) {
+	tests := []struct {
+		name        string
+		module      string
+		packageName string
+		expected    string
+	}{
+		{name: "package", packageName: "foo.bar", expected: "foo/bar/index.ts"},
+		{name: "no package", expected: "index.ts"},
+		{name: "module", module: "foo", packageName: "foo.bar", expected: "bar/index.ts"},
+		{name: "module not matching", module: "foo", packageName: "other", expected: "other/index.ts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				opts.Module = tt.module
+			})
+
+			assert.Equal(t, tt.expected, r.getIndexFileName(tt.packageName))
+		})
+	}
+}
+
+func TestGetFetchModulePath(t *testing.T) {
+	tests := []struct {
+		name                 string
+		module               string
+		fileSuffix           string
+		fetchModuleDirectory string
+		fetchModuleFilename  string
+		expected             string
+	}{
+		{name: "defaults", expected: "fetch.pb.ts"},
+		{name: "file suffix", fileSuffix: ".gen.ts", expected: "fetch.gen.ts"},
+		{name: "file name over file suffix", fileSuffix: ".gen.ts", fetchModuleFilename: "api.ts", expected: "api.ts"},
+		{name: "directory", fetchModuleDirectory: "foo/lib", expected: "foo/lib/fetch.pb.ts"},
+		{name: "module", module: "foo", fetchModuleDirectory: "foo/lib", expected: "lib/fetch.pb.ts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				opts.Module = tt.module
+				if tt.fileSuffix != "" {
+					opts.FileSuffix = tt.fileSuffix
+				}
+				if tt.fetchModuleDirectory != "" {
+					opts.FetchModuleDirectory = tt.fetchModuleDirectory
+				}
+				opts.FetchModuleFilename = tt.fetchModuleFilename
+			})
+
+			assert.Equal(t, tt.expected, r.GetFetchModulePath())
+		})
+	}
+}
+
+func TestGetClientFileName(t *testing.T) {
+	r := newTestRegistry(t, func(opts *Options) {
+		opts.ClientFileSuffix = ".client.ts"
+	})
+
+	assert.Equal(t, "foo/bar/baz.client.ts", r.getClientFileName("foo/bar/baz.pb.ts"))
+}
+
+func TestCheckOutputFiles(t *testing.T) {
+	tests := []struct {
+		name            string
+		change          func(opts *Options)
+		filesToGenerate []string
+		expected        map[string]string
+		expectedErr     string
+	}{
+		{
+			name:            "source relative",
+			filesToGenerate: []string{"shop/v1/book.proto", "store/v1/book.proto"},
+			expected:        map[string]string{"shop/v1/book.proto": "shop/v1/book.pb.ts", "store/v1/book.proto": "store/v1/book.pb.ts"},
+		},
+		{
+			name:            "same file with paths=import",
+			change:          func(opts *Options) { opts.Paths = PathsImport },
+			filesToGenerate: []string{"shop/v1/book.proto", "store/v1/book.proto"},
+			expectedErr:     "both shop/v1/book.proto and store/v1/book.proto are generated into shop/v1/book.pb.ts, change paths or rename one of them",
+		},
+		{
+			name:            "same file with paths=import and a dependency",
+			change:          func(opts *Options) { opts.Paths = PathsImport; opts.GenerateDependencies = true },
+			filesToGenerate: []string{"store/v1/book.proto", "shop/v1/order.proto"},
+			expected:        map[string]string{"common/money.proto": "common/money.pb.ts", "shop/v1/order.proto": "shop/v1/order.pb.ts", "store/v1/book.proto": "shop/v1/book.pb.ts"},
+		},
+		{
+			name:            "dependency outside of the module",
+			change:          func(opts *Options) { opts.Module = "shop" },
+			filesToGenerate: []string{"shop/v1/order.proto"},
+			expected:        map[string]string{"shop/v1/order.proto": "v1/order.pb.ts"},
+		},
+		{
+			name:            "dependency to generate outside of the module",
+			change:          func(opts *Options) { opts.Module = "shop"; opts.GenerateDependencies = true },
+			filesToGenerate: []string{"shop/v1/order.proto"},
+			expectedErr:     "generated file common/money.pb.ts for dependency common/money.proto does not start with the module prefix shop",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				if tt.change != nil {
+					tt.change(opts)
+				}
+			})
+
+			filesData, err := analyseFixture(t, r, "layout.textpb", tt.filesToGenerate...)
+			if tt.expectedErr != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.expectedErr, err.Error())
+				}
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			for name, expected := range tt.expected {
+				assert.Equal(t, expected, filesData[name].TSFileName, name)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+// pathVariableRegexp matches the variables inside the http rule path template, e.g. {name=shelves/*}
+var pathVariableRegexp = regexp.MustCompile("{([^}=]+)(=[^}]*)?}")
+
+// LintIssue is a construct found in the analysed files which will not work with grpc-gateway or break the generated TypeScript
+type LintIssue struct {
+	// Location is where the problematic element is defined
+	Location *data.Location
+	// Message describes the issue
+	Message string
+}
+
+// String formats the issue in the same way protoc reports errors
+func (i *LintIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Location, i.Message)
+}
+
+// Lint validates the analysed files to generate and the dependencies to generate, and returns the issues found in a stable order
+func (r *Registry) Lint(filesData map[string]*data.File) []*LintIssue {
+	fileNames := make([]string, 0, len(filesData))
+	for name := range filesData {
+		if r.IsFileToGenerate(name) || r.IsDependencyToGenerate(name) {
+			fileNames = append(fileNames, name)
+		}
+	}
+	sort.Strings(fileNames)
+
+	issues := make([]*LintIssue, 0)
+	for _, name := range fileNames {
+		for _, fileData := range filesData[name].OutputFiles() {
+			issues = append(issues, r.collisionIssues[fileData.TSFileName]...)
+			for _, service := range fileData.Services {
+				for _, method := range service.Methods {
+					issues = append(issues, r.lintMethod(method)...)
+				}
+
+				for _, method := range service.UnsupportedMethods {
+					issues = append(issues, &LintIssue{
+						Location: method.Location,
+						Message:  "client streaming is not supported by grpc-gateway, the method has been left out",
+					})
+				}
+			}
+		}
+	}
+
+	for _, issue := range issues {
+		r.resolvePositions(issue.Location)
+	}
+
+	return issues
+}
+
+// lintMethod checks the path variables and the body selector of the http rule against the request message
+func (r *Registry) lintMethod(method *data.Method) []*LintIssue {
+	issues := make([]*LintIssue, 0)
+	inputType, ok := r.Types[method.Input.Type]
+	if !ok || inputType.Message == nil {
+		return issues
+	}
+
+	for _, match := range pathVariableRegexp.FindAllStringSubmatch(method.URL, -1) {
+		if r.findFieldByPath(inputType.Message, strings.Split(match[1], ".")) == nil {
+			issues = append(issues, &LintIssue{
+				Location: method.Location,
+				Message:  fmt.Sprintf("path variable %s doesn't match any field in %s", match[1], strings.TrimPrefix(method.Input.Type, ".")),
+			})
+		}
+	}
+
+	if method.HTTPRequestBody == nil || *method.HTTPRequestBody == "" || *method.HTTPRequestBody == "*" {
+		return issues
+	}
+
+	body := *method.HTTPRequestBody
+	field := r.findFieldByPath(inputType.Message, []string{body})
+	if field == nil {
+		issues = append(issues, &LintIssue{
+			Location: method.Location,
+			Message:  fmt.Sprintf("body %s doesn't match any top level field in %s", body, strings.TrimPrefix(method.Input.Type, ".")),
+		})
+	} else if field.IsRepeated {
+		issues = append(issues, &LintIssue{
+			Location: method.Location,
+			Message:  fmt.Sprintf("body %s is a repeated field", body),
+		})
+	}
+
+	return issues
+}
+
+// checkIdentifierCollisions looks for the TypeScript identifiers declared more than once in the files to generate
+// and the dependencies to generate.
+// it fails on the first collision that cannot be left as a lint issue, the others are kept for Lint
+func (r *Registry) checkIdentifierCollisions(filesData map[string]*data.File) error {
+	fileNames := make([]string, 0, len(filesData))
+	for name := range filesData {
+		if r.IsFileToGenerate(name) || r.IsDependencyToGenerate(name) {
+			fileNames = append(fileNames, name)
+		}
+	}
+	sort.Strings(fileNames)
+
+	r.collisionIssues = make(map[string][]*LintIssue)
+	for _, name := range fileNames {
+		for _, fileData := range filesData[name].OutputFiles() {
+			issues, err := r.ForFile(fileData.Name, fileData.Package).identifierCollisions(fileData)
+			if err != nil {
+				return errors.Wrapf(err, "error analysing file %s", name)
+			}
+
+			r.collisionIssues[fileData.TSFileName] = issues
+		}
+	}
+
+	return nil
+}
+
+// identifierCollisions declares the top level TypeScript identifiers of the generated file, and reports the ones
+// declared more than once. the types colliding with each other are an error, as it comes from flattening the
+// nested types and a different nested_type_naming resolves it, while the other collisions are lint issues
+func (r *Registry) identifierCollisions(fileData *data.File) ([]*LintIssue, error) {
+	issues := make([]*LintIssue, 0)
+	declared := make(map[string]string)
+	declare := func(identifier, element string, location *data.Location) {
+		if previous, ok := declared[identifier]; ok {
+			issues = append(issues, &LintIssue{
+				Location: location,
+				Message:  fmt.Sprintf("TypeScript identifier %s collides with %s", identifier, previous),
+			})
+			return
+		}
+
+		declared[identifier] = element
+	}
+	declareType := func(identifier string, location *data.Location) error {
+		if previous, ok := declared[identifier]; ok {
+			r.resolvePositions(location)
+			return &ElementError{
+				Location: location,
+				Err: errors.Errorf("TypeScript identifier %s collides with %s, use a different %s to resolve it",
+					identifier, previous, NestedTypeNaming),
+			}
+		}
+
+		declared[identifier] = strings.TrimPrefix(location.Element, ".")
+		return nil
+	}
+
+	for _, enum := range fileData.Enums {
+		if err := declareType(enum.Identifier(), enum.Location); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, message := range fileData.Messages {
+		if err := declareType(message.Identifier(), message.Location); err != nil {
+			return nil, err
+		}
+	}
+
+	// the imports are allocated identifiers not taken by the types, so they only collide with the rest
+	for _, dependency := range fileData.StableDependencies() {
+		declared[dependency.ModuleIdentifier] = "the import of " + dependency.SourceFile
+	}
+
+	for _, message := range fileData.Messages {
+		if message.HasOneOfFields() {
+			base := &data.Message{Namespace: message.Namespace, Name: "Base" + message.Name}
+			declare(base.Identifier(), "the base type of "+strings.TrimPrefix(message.Location.Element, "."), message.Location)
+		}
+	}
+
+	for _, service := range fileData.Services {
+		if r.ServiceStyle != ServiceStyleFunctions {
+			declare(service.Name, strings.TrimPrefix(service.Location.Element, "."), service.Location)
+		}
+
+		if r.ServiceStyle == ServiceStyleClass {
+			continue
+		}
+
+		for _, method := range service.Methods {
+			element := strings.TrimPrefix(method.Location.Element, ".")
+			declare(service.FunctionName(method.Name), element, method.Location)
+			if method.LongRunning != nil {
+				declare(service.FunctionName(method.Name+"Operation"), "the operation function of "+element, method.Location)
+			}
+			for _, signature := range method.Signatures {
+				declare(service.FunctionName(signature.Name),
+					fmt.Sprintf("the function of method signature %q of %s", signatureFields(signature), element), method.Location)
+			}
+		}
+	}
+
+	return issues, nil
+}
@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		serviceStyle string
+		expected     []string
+	}{
+		{
+			serviceStyle: ServiceStyleClass,
+			expected: []string{
+				"lint/lint.proto:6:1: lint.Book: TypeScript identifier BaseBook collides with lint.BaseBook",
+				"lint/lint.proto:16:1: lint.Library: TypeScript identifier Library collides with lint.Library",
+				"lint/lint.proto:17:3: lint.Library.GetBook: path variable shelf.id doesn't match any field in lint.Book",
+				"lint/lint.proto:20:3: lint.Library.UpdateBook: body tags is a repeated field",
+				"lint/lint.proto:23:3: lint.Library.CreateBook: body book doesn't match any top level field in lint.Book",
+				"lint/lint.proto:26:3: lint.Library.UploadBooks: client streaming is not supported by grpc-gateway, the method has been left out",
+			},
+		},
+		{
+			// the standalone functions don't collide with the message named after the service
+			serviceStyle: ServiceStyleFunctions,
+			expected: []string{
+				"lint/lint.proto:6:1: lint.Book: TypeScript identifier BaseBook collides with lint.BaseBook",
+				"lint/lint.proto:17:3: lint.Library.GetBook: path variable shelf.id doesn't match any field in lint.Book",
+				"lint/lint.proto:20:3: lint.Library.UpdateBook: body tags is a repeated field",
+				"lint/lint.proto:23:3: lint.Library.CreateBook: body book doesn't match any top level field in lint.Book",
+				"lint/lint.proto:26:3: lint.Library.UploadBooks: client streaming is not supported by grpc-gateway, the method has been left out",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serviceStyle, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				opts.ServiceStyle = tt.serviceStyle
+			})
+
+			filesData, err := analyseFixture(t, r, "lint.textpb")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			issues := make([]string, 0)
+			for _, issue := range r.Lint(filesData) {
+				issues = append(issues, issue.String())
+			}
+			assert.Equal(t, tt.expected, issues)
+		})
+	}
+}
+
+func TestLintSignatureFunctions(t *testing.T) {
+	tests := []struct {
+		serviceStyle string
+		expected     []string
+	}{
+		{serviceStyle: ServiceStyleClass, expected: []string{}},
+		{
+			serviceStyle: ServiceStyleFunctions,
+			expected: []string{
+				`library/library.proto:22:3: library.LibraryGetBook.WithName: TypeScript identifier libraryGetBookWithName collides with the function of method signature "name" of library.Library.GetBook`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serviceStyle, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				opts.ServiceStyle = tt.serviceStyle
+			})
+
+			filesData, err := analyseFixture(t, r, "functions.textpb")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			issues := make([]string, 0)
+			for _, issue := range r.Lint(filesData) {
+				issues = append(issues, issue.String())
+			}
+			assert.Equal(t, tt.expected, issues)
+		})
+	}
+}
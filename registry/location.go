@@ -0,0 +1,140 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	descriptorpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+	"github.com/pkg/errors"
+)
+
+// field numbers inside the descriptors to build up the path of an element as defined in SourceCodeInfo.Location
+const (
+	fileMessageTypePath = 4
+	fileEnumTypePath    = 5
+	fileServicePath     = 6
+	messageFieldPath    = 2
+	messageNestedPath   = 3
+	messageEnumTypePath = 4
+	serviceMethodPath   = 2
+)
+
+// ElementError is an error caused by an element defined in a proto file, it carries
+// the information for users to find out the element in the proto
+type ElementError struct {
+	// Location is where the element is defined
+	Location *data.Location
+	// Err is the underlying error
+	Err error
+}
+
+// Error formats the error in the same way protoc reports errors, file:line:column: message
+func (e *ElementError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Location, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// newElementError creates an error for the element at the given path, the file and position will be filled in
+// by locateElementError when the error bubbles up to the file being analysed
+func newElementError(element string, path []int32, err error) *ElementError {
+	return &ElementError{
+		Location: &data.Location{
+			Element: element,
+			Path:    path,
+		},
+		Err: err,
+	}
+}
+
+// locateElementError fills in the file and position of the element error inside err if there is one.
+func locateElementError(f *descriptorpb.FileDescriptorProto, err error) error {
+	var elementErr *ElementError
+	if errors.As(err, &elementErr) && elementErr.Location.File == "" {
+		elementErr.Location.File = f.GetName()
+		newSourceLocations(f.GetSourceCodeInfo()).resolve(elementErr.Location)
+	}
+
+	return err
+}
+
+// newLocation creates the location for the element, line and column will be resolved with
+// resolvePositions when the location gets reported
+func newLocation(fileName, element string, path []int32) *data.Location {
+	return &data.Location{
+		File:    fileName,
+		Element: element,
+		Path:    path,
+	}
+}
+
+// sourceLocations looks up the positions of the elements inside SourceCodeInfo, the spans are indexed by
+// the joined paths of the elements on the first lookup, so that files without any issue reported are never indexed
+type sourceLocations struct {
+	info  *descriptorpb.SourceCodeInfo
+	spans map[string][]int32
+}
+
+func newSourceLocations(info *descriptorpb.SourceCodeInfo) *sourceLocations {
+	return &sourceLocations{info: info}
+}
+
+// resolve looks up the 1-based line and column of the location with its path inside SourceCodeInfo
+// the position stays 0 if it cannot be found
+func (s *sourceLocations) resolve(location *data.Location) {
+	if location == nil {
+		return
+	}
+
+	if s.spans == nil {
+		s.spans = make(map[string][]int32, len(s.info.GetLocation()))
+		for _, l := range s.info.GetLocation() {
+			key := joinPath(l.GetPath())
+			// the first location of a path wins, span always has 3 or 4 elements, starting with line and column
+			if _, ok := s.spans[key]; !ok && len(l.GetSpan()) >= 2 {
+				s.spans[key] = l.GetSpan()
+			}
+		}
+	}
+
+	if span, ok := s.spans[joinPath(location.Path)]; ok {
+		location.Line, location.Column = int(span[0])+1, int(span[1])+1
+	}
+}
+
+// resolvePositions looks up the line and column of the locations about to be reported
+func (r *Registry) resolvePositions(locations ...*data.Location) {
+	for _, location := range locations {
+		if location == nil {
+			continue
+		}
+
+		if s, ok := r.sourceLocations[location.File]; ok {
+			s.resolve(location)
+		}
+	}
+}
+
+func joinPath(path []int32) string {
+	var b strings.Builder
+	for i, p := range path {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(strconv.Itoa(int(p)))
+	}
+
+	return b.String()
+}
+
+// childPath returns a new path for the child element without mutating the parent's path
+func childPath(parent []int32, fieldNumber, index int) []int32 {
+	path := make([]int32, 0, len(parent)+2)
+	path = append(path, parent...)
+	return append(path, int32(fieldNumber), int32(index))
+}
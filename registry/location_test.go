@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"testing"
+
+	descriptorpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+func TestElementErrorLocation(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        *annotations.HttpRule
+		expectedErr string
+	}{
+		{
+			name:        "rule without a pattern",
+			rule:        &annotations.HttpRule{Body: "*"},
+			expectedErr: "library/library.proto:15:3: library.Library.MoveBook: unsupported HTTP method <nil> in the http rule",
+		},
+		{
+			name:        "custom rule without a kind",
+			rule:        &annotations.HttpRule{Pattern: &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Path: "/v1/{name}:move"}}},
+			expectedErr: "library/library.proto:15:3: library.Library.MoveBook: custom http rule needs both a kind and a path",
+		},
+		{
+			name:        "custom rule without a path",
+			rule:        &annotations.HttpRule{Pattern: &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Kind: "MOVE"}}},
+			expectedErr: "library/library.proto:15:3: library.Library.MoveBook: custom http rule needs both a kind and a path",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, names := loadFixture(t, "http_rule.textpb")
+			method := files[len(files)-1].Service[0].Method[1]
+			method.Options = &descriptorpb.MethodOptions{}
+			proto.SetExtension(method.Options, annotations.E_Http, tt.rule)
+
+			_, err := newTestRegistry(t, nil).Analyse(&plugin.CodeGeneratorRequest{FileToGenerate: names, ProtoFile: files})
+
+			var elementErr *ElementError
+			if assert.True(t, errors.As(err, &elementErr), "%v", err) {
+				assert.Equal(t, tt.expectedErr, elementErr.Error())
+			}
+		})
+	}
+}
+
+func TestSourceLocationsResolve(t *testing.T) {
+	files, _ := loadFixture(t, "http_rule.textpb")
+	locations := newSourceLocations(files[len(files)-1].GetSourceCodeInfo())
+
+	tests := []struct {
+		name           string
+		path           []int32
+		expectedLine   int
+		expectedColumn int
+	}{
+		{name: "file", path: []int32{}, expectedLine: 1, expectedColumn: 1},
+		{name: "service", path: []int32{fileServicePath, 0}, expectedLine: 10, expectedColumn: 1},
+		{name: "first location of the path", path: []int32{fileServicePath, 0, serviceMethodPath, 1}, expectedLine: 15, expectedColumn: 3},
+		{name: "unknown path", path: []int32{fileMessageTypePath, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := &data.Location{File: "library/library.proto", Path: tt.path}
+			locations.resolve(location)
+			assert.Equal(t, tt.expectedLine, location.Line)
+			assert.Equal(t, tt.expectedColumn, location.Column)
+		})
+	}
+}
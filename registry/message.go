@@ -6,7 +6,7 @@ import (
 	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
 )
 
-func (r *Registry) analyseMessage(fileData *data.File, packageName, fileName string, parents []string, message *descriptorpb.DescriptorProto) {
+func (r *Registry) analyseMessage(fileData *data.File, packageName, fileName string, parents []string, path []int32, message *descriptorpb.DescriptorProto) {
 	packageIdentifier := r.getNameOfPackageLevelIdentifier(parents, message.GetName())
 
 	fqName := r.getFullQualifiedName(packageName, parents, message.GetName()) // "." + packageName + "." + parentsPrefix + message.GetName()
@@ -53,19 +53,22 @@ func (r *Registry) analyseMessage(fileData *data.File, packageName, fileName str
 	}
 
 	data := data.NewMessage()
-	data.Name = packageIdentifier
+	data.Name = r.getLocalName(packageIdentifier, message.GetName())
+	data.Namespace = r.getNamespace(parents)
 	data.FQType = fqName
+	typeInfo.Message = data
+	data.Location = newLocation(fileName, fqName, path)
 
 	newParents := append(parents, message.GetName())
 
 	// handle enums, by pulling the enums out to the top level
-	for _, enum := range message.EnumType {
-		r.analyseEnumType(fileData, packageName, fileName, newParents, enum)
+	for i, enum := range message.EnumType {
+		r.analyseEnumType(fileData, packageName, fileName, newParents, childPath(path, messageEnumTypePath, i), enum)
 	}
 
 	// nested type also got pull out to the top level of the file
-	for _, msg := range message.NestedType {
-		r.analyseMessage(fileData, packageName, fileName, newParents, msg)
+	for i, msg := range message.NestedType {
+		r.analyseMessage(fileData, packageName, fileName, newParents, childPath(path, messageNestedPath, i), msg)
 	}
 
 	// store a map of one of names
@@ -74,8 +77,8 @@ func (r *Registry) analyseMessage(fileData *data.File, packageName, fileName str
 	}
 
 	// analyse fields in the messages
-	for _, f := range message.Field {
-		r.analyseField(fileData, data, packageName, f)
+	for i, f := range message.Field {
+		r.analyseField(fileData, data, packageName, newLocation(fileName, fqName+"."+f.GetName(), childPath(path, messageFieldPath, i)), f)
 	}
 
 	fileData.Messages = append(fileData.Messages, data)
@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNestedTypeNaming(t *testing.T) {
+	tests := []struct {
+		naming           string
+		expectedEnums    []string
+		expectedMessages []string
+		expectedTypes    map[string]string
+		expectedErr      string
+	}{
+		{
+			naming:      NestedTypeNamingConcat,
+			expectedErr: "nested/nested.proto:16:1: nested.FooBar: TypeScript identifier FooBar collides with nested.Foo.Bar, use a different nested_type_naming to resolve it",
+		},
+		{
+			naming:           NestedTypeNamingUnderscore,
+			expectedEnums:    []string{"Foo_Level"},
+			expectedMessages: []string{"Foo", "Foo_Bar", "Foo_Bar_Deep", "FooBar"},
+			expectedTypes:    map[string]string{".nested.Foo.Bar": "Foo_Bar", ".nested.Foo.Bar.Deep": "Foo_Bar_Deep", ".nested.Foo.Level": "Foo_Level"},
+		},
+		{
+			naming:           NestedTypeNamingNamespace,
+			expectedEnums:    []string{"Foo.Level"},
+			expectedMessages: []string{"Foo", "Foo.Bar", "Foo.Bar.Deep", "FooBar"},
+			expectedTypes:    map[string]string{".nested.Foo.Bar": "Foo.Bar", ".nested.Foo.Bar.Deep": "Foo.Bar.Deep", ".nested.Foo.Level": "Foo.Level"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.naming, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				opts.NestedTypeNaming = tt.naming
+			})
+
+			filesData, err := analyseFixture(t, r, "nested.textpb")
+			if tt.expectedErr != "" {
+				var elementErr *ElementError
+				if assert.True(t, errors.As(err, &elementErr), "%v", err) {
+					assert.Equal(t, tt.expectedErr, elementErr.Error())
+				}
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			fileData := filesData["nested/nested.proto"]
+			enums := make([]string, 0)
+			for _, enum := range fileData.Enums {
+				enums = append(enums, enum.Identifier())
+			}
+			messages := make([]string, 0)
+			for _, message := range fileData.Messages {
+				messages = append(messages, message.Identifier())
+			}
+			assert.Equal(t, tt.expectedEnums, enums)
+			assert.ElementsMatch(t, tt.expectedMessages, messages)
+
+			for typeName, expected := range tt.expectedTypes {
+				assert.Equal(t, expected, r.Types[typeName].PackageIdentifier, typeName)
+			}
+		})
+	}
+}
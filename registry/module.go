@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	log "github.com/sirupsen/logrus" // nolint: depguard
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+// fetchModuleIdentifier is the identifier the fetch module is imported as
+const fetchModuleIdentifier = "fm"
+
+// getModuleIdentifierCandidate names the module imported from the file with the configured naming scheme
+func (r *Registry) getModuleIdentifierCandidate(packageName, fileName string) string {
+	switch r.ModuleIdentifierNaming {
+	case ModuleIdentifierNamingFile:
+		base := filepath.Base(fileName)
+		return data.EscapeIdentifier(strcase.ToCamel(strings.TrimSuffix(base, filepath.Ext(base))))
+	case ModuleIdentifierNamingPath:
+		name := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+		return data.EscapeIdentifier(strcase.ToCamel(strings.ReplaceAll(name, "/", "_")))
+	default:
+		return data.GetModuleName(packageName, fileName)
+	}
+}
+
+// allocateModuleIdentifiers assigns the identifiers the dependencies are imported as inside the file.
+// candidates colliding with the identifiers declared in the file or with the ones allocated before get
+// a numeric suffix, the dependencies are processed in the order of the file names so that the result is stable
+func (r *Registry) allocateModuleIdentifiers(fileData *data.File, dependencies map[string]*data.Dependency, dependencyTypes map[string]*TypeInformation) {
+	taken := localIdentifiers(fileData)
+
+	keys := make([]string, 0, len(dependencies))
+	for key := range dependencies {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return dependencyTypes[keys[i]].File < dependencyTypes[keys[j]].File
+	})
+
+	for _, key := range keys {
+		typeInfo := dependencyTypes[key]
+		candidate := r.getModuleIdentifierCandidate(typeInfo.Package, typeInfo.File)
+		identifier := candidate
+		for i := 2; taken[identifier]; i++ {
+			identifier = candidate + strconv.Itoa(i)
+		}
+
+		if identifier != candidate {
+			log.Debugf("module identifier %s for %s has been taken in %s, using %s instead", candidate, typeInfo.File, fileData.Name, identifier)
+		}
+
+		taken[identifier] = true
+		dependencies[key].ModuleIdentifier = identifier
+		fileData.ModuleIdentifiers[typeInfo.File] = identifier
+	}
+}
+
+// localIdentifiers returns the top level identifiers declared in the file, which the imported modules must not shadow
+func localIdentifiers(fileData *data.File) map[string]bool {
+	identifiers := map[string]bool{fetchModuleIdentifier: true}
+	root := func(identifier string) string {
+		return strings.Split(identifier, ".")[0]
+	}
+
+	for _, enum := range fileData.Enums {
+		identifiers[root(enum.Identifier())] = true
+	}
+
+	for _, message := range fileData.Messages {
+		identifiers[root(message.Identifier())] = true
+		if message.HasOneOfFields() && message.Namespace == "" {
+			identifiers["Base"+message.Name] = true
+		}
+	}
+
+	for _, service := range fileData.Services {
+		identifiers[service.Name] = true
+	}
+
+	return identifiers
+}
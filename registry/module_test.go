@@ -0,0 +1,106 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+func TestGetModuleIdentifierCandidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		naming      string
+		packageName string
+		fileName    string
+		expected    string
+	}{
+		{name: "package file", naming: ModuleIdentifierNamingPackageFile, packageName: "foo.bar", fileName: "foo/bar/baz.proto", expected: "FooBarBaz"},
+		{name: "package file without package", naming: ModuleIdentifierNamingPackageFile, fileName: "baz.proto", expected: "Baz"},
+		{name: "package file escaped", naming: ModuleIdentifierNamingPackageFile, fileName: "date.proto", expected: "Date_"},
+		{name: "file", naming: ModuleIdentifierNamingFile, packageName: "foo.bar", fileName: "foo/bar/baz_qux.proto", expected: "BazQux"},
+		{name: "file escaped", naming: ModuleIdentifierNamingFile, packageName: "google.type", fileName: "google/type/date.proto", expected: "Date_"},
+		{name: "path", naming: ModuleIdentifierNamingPath, packageName: "google.type", fileName: "google/type/date.proto", expected: "GoogleTypeDate"},
+		{name: "path with underscores", naming: ModuleIdentifierNamingPath, packageName: "foo", fileName: "foo/bar_baz.proto", expected: "FooBarBaz"},
+		{name: "path escaped", naming: ModuleIdentifierNamingPath, fileName: "error.proto", expected: "Error_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				opts.ModuleIdentifierNaming = tt.naming
+			})
+
+			assert.Equal(t, tt.expected, r.getModuleIdentifierCandidate(tt.packageName, tt.fileName))
+		})
+	}
+}
+
+func TestAllocateModuleIdentifiers(t *testing.T) {
+	tests := []struct {
+		name     string
+		naming   string
+		files    map[string]string
+		messages []*data.Message
+		services []string
+		expected map[string]string
+	}{
+		{
+			name:     "no collisions",
+			naming:   ModuleIdentifierNamingPackageFile,
+			files:    map[string]string{"foo/a.proto": "foo", "bar/b.proto": "bar"},
+			expected: map[string]string{"foo/a.proto": "FooA", "bar/b.proto": "BarB"},
+		},
+		{
+			name:     "collisions between dependencies in the order of the file names",
+			naming:   ModuleIdentifierNamingFile,
+			files:    map[string]string{"c/book.proto": "c", "a/book.proto": "a", "b/book.proto": "b"},
+			expected: map[string]string{"a/book.proto": "Book", "b/book.proto": "Book2", "c/book.proto": "Book3"},
+		},
+		{
+			name:     "collisions with the messages",
+			naming:   ModuleIdentifierNamingFile,
+			files:    map[string]string{"a/book.proto": "a", "a/shelf.proto": "a"},
+			messages: []*data.Message{{Name: "Book"}, {Name: "Author", Namespace: "Shelf"}},
+			expected: map[string]string{"a/book.proto": "Book2", "a/shelf.proto": "Shelf2"},
+		},
+		{
+			name:     "collisions with the services and the escaped names",
+			naming:   ModuleIdentifierNamingFile,
+			files:    map[string]string{"a/library.proto": "a", "a/date.proto": "a", "b/date.proto": "b"},
+			services: []string{"Library"},
+			expected: map[string]string{"a/library.proto": "Library2", "a/date.proto": "Date_", "b/date.proto": "Date_2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				opts.ModuleIdentifierNaming = tt.naming
+			})
+
+			fileData := data.NewFile()
+			fileData.Name = "main.proto"
+			fileData.Messages = tt.messages
+			for _, service := range tt.services {
+				fileData.Services = append(fileData.Services, &data.Service{Name: service})
+			}
+
+			dependencies := make(map[string]*data.Dependency)
+			dependencyTypes := make(map[string]*TypeInformation)
+			for fileName, packageName := range tt.files {
+				key := "." + packageName + "." + fileName
+				dependencies[key] = &data.Dependency{}
+				dependencyTypes[key] = &TypeInformation{File: fileName, Package: packageName}
+			}
+
+			r.allocateModuleIdentifiers(fileData, dependencies, dependencyTypes)
+
+			assert.Equal(t, tt.expected, fileData.ModuleIdentifiers)
+			for key, dependency := range dependencies {
+				assert.Equal(t, tt.expected[dependencyTypes[key].File], dependency.ModuleIdentifier)
+			}
+		})
+	}
+}
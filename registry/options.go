@@ -0,0 +1,170 @@
+package registry
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Options are the typed equivalent of the parameters of the registry. empty strings stand for the defaults of the
+// parameters, DefaultOptions returns the options with all the defaults filled in, which is what the plugin starts from
+type Options struct {
+	// TSImportRoots are the directories the proto imports are looked up from, defaults to the current directory
+	TSImportRoots []string
+	// TSImportRootAliases are the aliases of the ts import roots of the same index in the import specifiers
+	TSImportRootAliases []string
+	// FetchModuleDirectory is the directory of the generated fetch module, defaults to .
+	FetchModuleDirectory string
+	// FetchModuleFilename is the file name of the generated fetch module, defaults to fetch followed by FileSuffix
+	FetchModuleFilename string
+	// FetchModulePackage is the module specifier to import the fetch module from instead of generating it
+	FetchModulePackage string
+	// FetchModulePackageVersion is the fetch module API version of FetchModulePackage, it is required along with it
+	FetchModulePackageVersion string
+	// FetchModuleFeatures is the features included in the generated fetch module, either used or all
+	FetchModuleFeatures string
+	// UseProtoNames uses the field names in the proto instead of the json names
+	UseProtoNames bool
+	// FieldMaskDepth is the depth of the field mask paths generated for each message, 0 disables the generation
+	FieldMaskDepth int
+	// GrpcAPIConfiguration is the path to the gateway service config YAML with the http rules
+	GrpcAPIConfiguration string
+	// OmitUnboundMethods skips the methods without http rules, which are otherwise generated with a synthesised POST path
+	// as generate_unbound_methods defaults to true
+	OmitUnboundMethods bool
+	// NestedTypeNaming is the strategy to name the nested types, one of concat, underscore or namespace
+	NestedTypeNaming string
+	// ModuleIdentifierNaming is the scheme to name the imported modules, one of package_file, file or path
+	ModuleIdentifierNaming string
+	// Paths is the layout of the generated files, either source_relative or import
+	Paths string
+	// Module is the prefix stripped from the generated file names
+	Module string
+	// FileSuffix is the suffix replacing .proto in the generated file names, defaults to DefaultFileSuffix
+	FileSuffix string
+	// ClientFileSuffix is the suffix of the client files the services are generated into, empty to generate
+	// the services along with the types
+	ClientFileSuffix string
+	// GenerateIndexFiles generates an index file for every package
+	GenerateIndexFiles bool
+	// GenerateDependencies generates the files the files to generate depend on transitively
+	GenerateDependencies bool
+	// ServiceStyle is how the services are rendered, one of class, functions or functions_and_class
+	ServiceStyle string
+	// ImportResolution is how the imports of other generated files are resolved, either filesystem or hermetic
+	ImportResolution string
+	// ImportExtension is the extension of the imports between the generated files, one of none, .js, .ts or .mjs,
+	// defaults to .js with TargetJS and none otherwise. .mjs is only supported with TargetJS
+	ImportExtension string
+	// Target is the language of the generated files, either ts or js
+	Target string
+	// ImportMappings maps the proto files, or the directories ending with /, to the import specifiers
+	// in the same way as the M parameters
+	ImportMappings map[string]string
+	// Overrides are the parameters overridden per package or per file
+	Overrides []*Override
+}
+
+// DefaultOptions returns the options with the defaults of the parameters
+func DefaultOptions() Options {
+	defaults := make(map[string]string)
+	for _, p := range Params {
+		if p.Default != "" {
+			defaults[p.Name] = p.Default
+		}
+	}
+
+	opts := Options{ImportMappings: make(map[string]string)}
+	if err := opts.apply(defaults); err != nil {
+		// the defaults are valid values of the parameters, an error is a mistake in Params
+		panic(err)
+	}
+
+	return opts
+}
+
+// ParseOptions validates the parameters and turns them into the options, the parameters not specified keep the defaults
+func ParseOptions(paramsMap map[string]string) (*Options, error) {
+	if err := ValidateParams(paramsMap); err != nil {
+		return nil, err
+	}
+
+	opts := DefaultOptions()
+	if err := opts.apply(paramsMap); err != nil {
+		return nil, err
+	}
+
+	c, err := loadConfig(paramsMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading the config file")
+	}
+
+	if tsImportRoots, ok := c.lists[TSImportRootParamsKey]; ok {
+		opts.TSImportRoots = tsImportRoots
+	}
+	if tsImportRootAliases, ok := c.lists[TSImportRootAliasParamsKey]; ok {
+		opts.TSImportRootAliases = tsImportRootAliases
+	}
+	opts.Overrides = c.overrides
+
+	return &opts, nil
+}
+
+// apply sets the options from the validated parameters, the other parameters are left untouched
+func (opts *Options) apply(paramsMap map[string]string) error {
+	strs := map[string]*string{
+		FetchModuleDirectory:      &opts.FetchModuleDirectory,
+		FetchModuleFileName:       &opts.FetchModuleFilename,
+		FetchModulePackage:        &opts.FetchModulePackage,
+		FetchModulePackageVersion: &opts.FetchModulePackageVersion,
+		FetchModuleFeatures:       &opts.FetchModuleFeatures,
+		GrpcAPIConfiguration:      &opts.GrpcAPIConfiguration,
+		NestedTypeNaming:          &opts.NestedTypeNaming,
+		ModuleIdentifierNaming:    &opts.ModuleIdentifierNaming,
+		Paths:                     &opts.Paths,
+		Module:                    &opts.Module,
+		FileSuffix:                &opts.FileSuffix,
+		ClientFileSuffix:          &opts.ClientFileSuffix,
+		ServiceStyle:              &opts.ServiceStyle,
+		ImportResolution:          &opts.ImportResolution,
+		ImportExtension:           &opts.ImportExtension,
+		Target:                    &opts.Target,
+	}
+	bools := map[string]*bool{
+		UseProtoNames:        &opts.UseProtoNames,
+		GenerateIndexFiles:   &opts.GenerateIndexFiles,
+		GenerateDependencies: &opts.GenerateDependencies,
+	}
+
+	for key, value := range paramsMap {
+		if s, ok := strs[key]; ok {
+			*s = value
+		} else if b, ok := bools[key]; ok {
+			*b = value == "true"
+		} else if strings.HasPrefix(key, ImportMappingPrefix) && len(key) > len(ImportMappingPrefix) {
+			opts.ImportMappings[strings.TrimPrefix(key, ImportMappingPrefix)] = value
+		}
+	}
+
+	if tsImportRoots, ok := paramsMap[TSImportRootParamsKey]; ok {
+		opts.TSImportRoots = strings.Split(tsImportRoots, TSImportRootSeparator)
+	}
+
+	if tsImportRootAliases, ok := paramsMap[TSImportRootAliasParamsKey]; ok {
+		opts.TSImportRootAliases = strings.Split(tsImportRootAliases, TSImportRootSeparator)
+	}
+
+	if generateUnboundMethods, ok := paramsMap[GenerateUnboundMethods]; ok {
+		opts.OmitUnboundMethods = generateUnboundMethods != "true"
+	}
+
+	if fieldMaskDepthVal, ok := paramsMap[FieldMaskDepth]; ok {
+		fieldMaskDepth, err := parseFieldMaskDepth(fieldMaskDepthVal)
+		if err != nil {
+			return err
+		}
+		opts.FieldMaskDepth = fieldMaskDepth
+	}
+
+	return nil
+}
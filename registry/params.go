@@ -0,0 +1,234 @@
+package registry
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParamKind is the kind of value a parameter takes
+type ParamKind string
+
+const (
+	// ParamKindString takes any string
+	ParamKindString ParamKind = "string"
+	// ParamKindBool takes either true or false
+	ParamKindBool ParamKind = "bool"
+	// ParamKindInt takes an integer
+	ParamKindInt ParamKind = "int"
+	// ParamKindList takes a list of strings separated by TSImportRootSeparator
+	ParamKindList ParamKind = "list"
+)
+
+// Param describes a parameter accepted by the plugin
+type Param struct {
+	// Name is the key of the parameter
+	Name string
+	// Kind is the kind of value the parameter takes
+	Kind ParamKind
+	// Default is the value used when the parameter is not specified, empty if there is none
+	Default string
+	// Description is a one line description of the parameter
+	Description string
+	// Values are the values the parameter accepts, any value of the kind is accepted if empty
+	Values []string
+}
+
+// Params are all the parameters accepted by the plugin, including the ones only read by the generator.
+// the import mapping parameters are not listed as their keys start with ImportMappingPrefix followed by the proto path.
+// the defaults are the ones DefaultOptions and NewRegistryFromOptions fall back to
+var Params = []*Param{
+	{Name: TSImportRootParamsKey, Kind: ParamKindList, Default: ".", Description: "the directories the proto imports are looked up from"},
+	{Name: TSImportRootAliasParamsKey, Kind: ParamKindList, Description: "the aliases of the ts import roots in the import specifiers"},
+	{Name: FetchModuleDirectory, Kind: ParamKindString, Default: ".", Description: "the directory of the generated fetch module"},
+	{Name: FetchModuleFileName, Kind: ParamKindString, Description: "the file name of the generated fetch module, fetch followed by file_suffix by default"},
+	{Name: FetchModulePackage, Kind: ParamKindString, Description: "the module specifier to import the fetch module from instead of generating it"},
+	{Name: FetchModulePackageVersion, Kind: ParamKindString, Description: "the fetch module API version of the fetch module package"},
+	{Name: FetchModuleFeatures, Kind: ParamKindString, Default: FetchModuleFeaturesAll, Description: "the features included in the generated fetch module",
+		Values: []string{FetchModuleFeaturesUsed, FetchModuleFeaturesAll}},
+	{Name: UseProtoNames, Kind: ParamKindBool, Default: "false", Description: "use the field names in the proto instead of the json names"},
+	{Name: FieldMaskDepth, Kind: ParamKindInt, Default: "0", Description: "the depth of the field mask paths generated for each message"},
+	{Name: GrpcAPIConfiguration, Kind: ParamKindString, Description: "the gateway service config YAML with the http rules"},
+	{Name: GenerateUnboundMethods, Kind: ParamKindBool, Default: "true", Description: "generate the methods without http rules with a POST path"},
+	{Name: NestedTypeNaming, Kind: ParamKindString, Default: NestedTypeNamingConcat, Description: "the strategy to name the nested types",
+		Values: []string{NestedTypeNamingConcat, NestedTypeNamingUnderscore, NestedTypeNamingNamespace}},
+	{Name: ModuleIdentifierNaming, Kind: ParamKindString, Default: ModuleIdentifierNamingPackageFile, Description: "the scheme to name the imported modules",
+		Values: []string{ModuleIdentifierNamingPackageFile, ModuleIdentifierNamingFile, ModuleIdentifierNamingPath}},
+	{Name: Paths, Kind: ParamKindString, Default: PathsSourceRelative, Description: "the layout of the generated files",
+		Values: []string{PathsSourceRelative, PathsImport}},
+	{Name: Module, Kind: ParamKindString, Description: "the prefix stripped from the generated file names"},
+	{Name: FileSuffix, Kind: ParamKindString, Default: DefaultFileSuffix, Description: "the suffix replacing .proto in the generated file names"},
+	{Name: ClientFileSuffix, Kind: ParamKindString, Description: "the suffix of the client files holding the services"},
+	{Name: GenerateIndexFiles, Kind: ParamKindBool, Default: "false", Description: "generate an index file for every package"},
+	{Name: GenerateDependencies, Kind: ParamKindBool, Default: "false", Description: "generate the files the files to generate depend on"},
+	{Name: ServiceStyle, Kind: ParamKindString, Default: ServiceStyleClass, Description: "how the services are rendered",
+		Values: []string{ServiceStyleClass, ServiceStyleFunctions, ServiceStyleFunctionsAndClass}},
+	{Name: ImportResolution, Kind: ParamKindString, Default: ImportResolutionFilesystem, Description: "how the imports of other generated files are resolved",
+		Values: []string{ImportResolutionFilesystem, ImportResolutionHermetic}},
+	{Name: ImportExtension, Kind: ParamKindString, Description: "the extension of the imports between the generated files, .js with target js and none otherwise",
+		Values: []string{ImportExtensionNone, ".js", ".ts", ".mjs"}},
+	{Name: Target, Kind: ParamKindString, Default: TargetTS, Description: "the language of the generated files",
+		Values: []string{TargetTS, TargetJS}},
+	{Name: EnableStylingCheck, Kind: ParamKindBool, Default: "false", Description: "format the generated files to check the styling"},
+	{Name: LintStrict, Kind: ParamKindBool, Default: "false", Description: "fail the generation on lint issues instead of warning"},
+	{Name: ConfigFile, Kind: ParamKindString, Description: "the YAML or JSON file with the parameters"},
+	{Name: "logtostderr", Kind: ParamKindBool, Default: "false", Description: "turn on logging to stderr"},
+	{Name: "loglevel", Kind: ParamKindString, Default: "info", Description: "the logging level",
+		Values: []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}},
+}
+
+// LookupParam returns the parameter with the name
+func LookupParam(name string) (*Param, bool) {
+	for _, p := range Params {
+		if p.Name == name {
+			return p, true
+		}
+	}
+
+	return nil, false
+}
+
+// paramDefault returns the default of the parameter, empty if the parameter has none
+func paramDefault(name string) string {
+	if p, ok := LookupParam(name); ok {
+		return p.Default
+	}
+
+	return ""
+}
+
+// paramValue validates the value of the parameter, the default of the parameter is returned if the value is empty
+func paramValue(name, value string) (string, error) {
+	if value == "" {
+		return paramDefault(name), nil
+	}
+
+	if err := validateParam(name, value); err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+// validateParam validates the value of the parameter against the parameter in Params
+func validateParam(name, value string) error {
+	p, ok := LookupParam(name)
+	if !ok {
+		// the parameters are looked up by their constants, an error is a mistake in Params
+		return errors.Errorf("unknown parameter %s", name)
+	}
+
+	return p.Validate(value)
+}
+
+// Validate checks the value is of the kind of the parameter and is one of the values the parameter accepts
+func (p *Param) Validate(value string) error {
+	switch p.Kind {
+	case ParamKindBool:
+		if value != "true" && value != "false" {
+			return errors.Errorf("invalid %s %s, it needs to be either true or false", p.Name, value)
+		}
+	case ParamKindInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return errors.Errorf("invalid %s %s, it needs to be an integer", p.Name, value)
+		}
+	}
+
+	if len(p.Values) == 0 {
+		return nil
+	}
+
+	for _, v := range p.Values {
+		if v == value {
+			return nil
+		}
+	}
+
+	return errors.Errorf("invalid %s %s, it needs to be one of %s", p.Name, value, strings.Join(p.Values, ", "))
+}
+
+// ValidateParams checks the parameters against Params, all the unknown parameters and invalid values are reported
+// at once so that they can be fixed in one go
+func ValidateParams(paramsMap map[string]string) error {
+	names := make([]string, 0, len(paramsMap))
+	for name := range paramsMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, 0)
+	for _, name := range names {
+		if name == "" || (strings.HasPrefix(name, ImportMappingPrefix) && len(name) > len(ImportMappingPrefix)) {
+			// empty parameters come from trailing commas, and the import mappings are checked with the mappings themselves
+			continue
+		}
+
+		p, ok := LookupParam(name)
+		if !ok {
+			messages = append(messages, unknownParamMessage(name))
+			continue
+		}
+
+		if err := p.Validate(paramsMap[name]); err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	if len(messages) == 0 {
+		return nil
+	}
+
+	return errors.Errorf("%d invalid parameters found:\n%s", len(messages), strings.Join(messages, "\n"))
+}
+
+// unknownParamMessage suggests the closest parameter, which is most likely what has been meant by a typo
+func unknownParamMessage(name string) string {
+	closest, closestDistance := "", len(name)
+	for _, p := range Params {
+		if distance := editDistance(name, p.Name); distance < closestDistance {
+			closest, closestDistance = p.Name, distance
+		}
+	}
+
+	if closest == "" || closestDistance > 2 {
+		return "unknown parameter " + name
+	}
+
+	return "unknown parameter " + name + ", did you mean " + closest + "?"
+}
+
+// editDistance is the Levenshtein distance between the strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,144 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   map[string]string
+		expected string
+	}{
+		{name: "no params", params: map[string]string{}},
+		{name: "trailing comma", params: map[string]string{"": ""}},
+		{
+			name: "valid params",
+			params: map[string]string{
+				UseProtoNames:         "true",
+				FieldMaskDepth:        "2",
+				ServiceStyle:          ServiceStyleFunctions,
+				TSImportRootParamsKey: ".;../protos",
+				"Mfoo/bar.proto":      "@foo/bar",
+			},
+		},
+		// the parameters read by the generator are declared along with the ones of the registry
+		{name: "generator params", params: map[string]string{EnableStylingCheck: "true", LintStrict: "false"}},
+		{name: "invalid generator param", params: map[string]string{LintStrict: "on"}, expected: "1 invalid parameters found:\ninvalid lint_strict on, it needs to be either true or false"},
+		{name: "unknown param", params: map[string]string{"foo": "bar"}, expected: "1 invalid parameters found:\nunknown parameter foo"},
+		{name: "invalid bool", params: map[string]string{UseProtoNames: "yes"}, expected: "1 invalid parameters found:\ninvalid use_proto_names yes, it needs to be either true or false"},
+		{name: "invalid int", params: map[string]string{FieldMaskDepth: "deep"}, expected: "1 invalid parameters found:\ninvalid field_mask_depth deep, it needs to be an integer"},
+		{
+			name:     "value not accepted",
+			params:   map[string]string{Paths: "relative"},
+			expected: "1 invalid parameters found:\ninvalid paths relative, it needs to be one of source_relative, import",
+		},
+		{name: "empty import mapping", params: map[string]string{ImportMappingPrefix: "foo"}, expected: "1 invalid parameters found:\nunknown parameter M"},
+		{
+			name:     "all reported sorted by name",
+			params:   map[string]string{Target: "py", "servce_style": "class", GenerateIndexFiles: "1"},
+			expected: "3 invalid parameters found:\ninvalid generate_index_files 1, it needs to be either true or false\nunknown parameter servce_style, did you mean service_style?\ninvalid target py, it needs to be one of ts, js",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateParams(tt.params)
+			if tt.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestUnknownParamMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "use_proto_name", expected: "unknown parameter use_proto_name, did you mean use_proto_names?"},
+		{name: "fetch_modul_package", expected: "unknown parameter fetch_modul_package, did you mean fetch_module_package?"},
+		{name: "tagret", expected: "unknown parameter tagret, did you mean target?"},
+		{name: "servicestyle", expected: "unknown parameter servicestyle, did you mean service_style?"},
+		{name: "output", expected: "unknown parameter output"},
+		{name: "x", expected: "unknown parameter x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, unknownParamMessage(tt.name))
+		})
+	}
+}
+
+func TestDefaultOptionsFollowParams(t *testing.T) {
+	opts := DefaultOptions()
+
+	assert.Equal(t, []string{paramDefault(TSImportRootParamsKey)}, opts.TSImportRoots)
+	assert.Equal(t, paramDefault(FetchModuleDirectory), opts.FetchModuleDirectory)
+	assert.Equal(t, "", opts.FetchModuleFilename)
+	assert.Equal(t, paramDefault(FetchModuleFeatures), opts.FetchModuleFeatures)
+	assert.Equal(t, paramDefault(FileSuffix), opts.FileSuffix)
+	assert.Equal(t, paramDefault(ServiceStyle), opts.ServiceStyle)
+	assert.Equal(t, paramDefault(Target), opts.Target)
+	assert.Equal(t, "", opts.ImportExtension)
+	assert.False(t, opts.OmitUnboundMethods)
+	assert.Equal(t, 0, opts.FieldMaskDepth)
+}
+
+func TestRegistryOptionsFollowParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        func(*Options)
+		expectedErr string
+	}{
+		{
+			name:        "value not in params",
+			opts:        func(opts *Options) { opts.NestedTypeNaming = "camel" },
+			expectedErr: "invalid nested_type_naming camel, it needs to be one of concat, underscore, namespace",
+		},
+		{
+			name:        "import extension not in params",
+			opts:        func(opts *Options) { opts.ImportExtension = ".cjs" },
+			expectedErr: "invalid import_extension .cjs, it needs to be one of none, .js, .ts, .mjs",
+		},
+		{
+			name:        "mjs with target ts",
+			opts:        func(opts *Options) { opts.ImportExtension = ".mjs" },
+			expectedErr: "import_extension .mjs needs target js, which generates the .mjs files",
+		},
+		{
+			name: "none with target js",
+			opts: func(opts *Options) {
+				opts.Target = TargetJS
+				opts.ImportExtension = ImportExtensionNone
+			},
+			expectedErr: "import_extension none cannot be used with target js",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			tt.opts(&opts)
+			_, err := NewRegistryFromOptions(opts)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+
+	// the options left empty take the defaults in params
+	r, err := NewRegistryFromOptions(Options{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, paramDefault(NestedTypeNaming), r.NestedTypeNaming)
+		assert.Equal(t, paramDefault(Paths), r.Paths)
+		assert.Equal(t, paramDefault(Target), r.Target)
+		assert.Equal(t, "", r.ImportExtension)
+	}
+}
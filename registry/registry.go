@@ -4,6 +4,8 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	descriptorpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
@@ -11,6 +13,7 @@ import (
 	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus" // nolint: depguard
+	"google.golang.org/genproto/googleapis/api/annotations"
 )
 
 const (
@@ -24,8 +27,100 @@ const (
 	FetchModuleDirectory = "fetch_module_directory"
 	// FetchModuleFileName is the file name for the individual fetch module
 	FetchModuleFileName = "fetch_module_filename"
+	// FetchModulePackage is the parameter for the module specifier to import the fetch module from instead of generating it
+	FetchModulePackage = "fetch_module_package"
+	// FetchModulePackageVersion is the parameter for the runtime API version of the fetch module package
+	FetchModulePackageVersion = "fetch_module_package_version"
+	// FetchModuleFeatures is the parameter for the features to include in the generated fetch module
+	FetchModuleFeatures = "fetch_module_features"
+	// FetchModuleFeaturesUsed includes the features used by the generated files only, the exported API of the fetch module
+	// then depends on the files generated in the same protoc invocation
+	FetchModuleFeaturesUsed = "used"
+	// FetchModuleFeaturesAll includes all the features, which is the default so that the exported API of the fetch module
+	// stays the same across protoc invocations generating into the same directory
+	FetchModuleFeaturesAll = "all"
+	// FetchModuleAPIVersion is the version of the API exported by the fetch module, it needs to be bumped
+	// whenever the generated code relies on a change to the fetch module
+	FetchModuleAPIVersion = "1"
 	// UseProtoNames will make the generator to generate field name the same as defined in the proto
 	UseProtoNames = "use_proto_names"
+	// FieldMaskDepth is the parameter for the depth of nested field mask paths generated for each message, 0 disables the generation
+	FieldMaskDepth = "field_mask_depth"
+	// GrpcAPIConfiguration is the parameter for the gateway service config YAML containing external http rules
+	GrpcAPIConfiguration = "grpc_api_configuration"
+	// GenerateUnboundMethods mirrors the grpc-gateway flag, methods without http rules will be generated with a synthesised POST /package.Service/Method path when turned on
+	GenerateUnboundMethods = "generate_unbound_methods"
+	// NestedTypeNaming is the parameter for the strategy to name nested messages and enums in TypeScript
+	NestedTypeNaming = "nested_type_naming"
+	// NestedTypeNamingConcat concatenates the names of the parents and the nested type, e.g. Foo.Bar becomes FooBar
+	NestedTypeNamingConcat = "concat"
+	// NestedTypeNamingUnderscore joins the names of the parents and the nested type with underscores, e.g. Foo.Bar becomes Foo_Bar
+	NestedTypeNamingUnderscore = "underscore"
+	// NestedTypeNamingNamespace renders the nested types inside TypeScript namespaces named after the parents, e.g. Foo.Bar stays Foo.Bar
+	NestedTypeNamingNamespace = "namespace"
+	// ModuleIdentifierNaming is the parameter for the scheme to name the modules imported from other files
+	ModuleIdentifierNaming = "module_identifier_naming"
+	// ModuleIdentifierNamingPackageFile joins the package and the file base name, e.g. foo.bar/baz.proto becomes FooBarBaz
+	ModuleIdentifierNamingPackageFile = "package_file"
+	// ModuleIdentifierNamingFile uses the file base name only, e.g. foo.bar/baz.proto becomes Baz
+	ModuleIdentifierNamingFile = "file"
+	// ModuleIdentifierNamingPath uses the path of the file, e.g. foo/bar/baz.proto becomes FooBarBaz
+	ModuleIdentifierNamingPath = "path"
+	// Paths is the parameter for the layout of the generated files, similar to the paths flag of protoc-gen-go
+	Paths = "paths"
+	// PathsSourceRelative places the generated files in the same directories as the proto files
+	PathsSourceRelative = "source_relative"
+	// PathsImport places the generated files in the directories named after the proto packages, e.g. foo.bar becomes foo/bar
+	PathsImport = "import"
+	// Module is the parameter for the prefix to strip from the generated file names, similar to the module flag of protoc-gen-go
+	Module = "module"
+	// FileSuffix is the parameter for the suffix replacing .proto in the generated file names
+	FileSuffix = "file_suffix"
+	// DefaultFileSuffix is the default suffix of the generated files
+	DefaultFileSuffix = ".pb.ts"
+	// ClientFileSuffix is the parameter for the suffix of the client files, setting it separates the services from the types
+	// into the client files, e.g. foo.pb.ts and foo.client.ts, so that the types don't depend on the fetch module
+	ClientFileSuffix = "client_file_suffix"
+	// GenerateIndexFiles is the parameter to generate an index.ts for every package, re-exporting the files generated for it
+	GenerateIndexFiles = "generate_index_files"
+	// GenerateDependencies is the parameter to generate the files the files to generate depend on as well
+	GenerateDependencies = "generate_dependencies"
+	// ServiceStyle is the parameter for how the services are rendered
+	ServiceStyle = "service_style"
+	// ServiceStyleClass renders the services as classes with static methods
+	ServiceStyleClass = "class"
+	// ServiceStyleFunctions renders each method as an exported standalone function, which can be tree-shaken by bundlers
+	ServiceStyleFunctions = "functions"
+	// ServiceStyleFunctionsAndClass renders the standalone functions, as well as the classes composed from them
+	ServiceStyleFunctionsAndClass = "functions_and_class"
+	// ImportResolution is the parameter for how the import specifiers of other generated files are resolved
+	ImportResolution = "import_resolution"
+	// ImportResolutionFilesystem looks up the imported proto files under ts_import_roots on the filesystem
+	ImportResolutionFilesystem = "filesystem"
+	// ImportResolutionHermetic computes the import specifiers from the proto paths and the output layout only,
+	// without looking at the filesystem or the current directory
+	ImportResolutionHermetic = "hermetic"
+	// ImportExtension is the parameter for the extension of the imports between the generated files, one of none, .js, .ts or .mjs
+	ImportExtension = "import_extension"
+	// ImportExtensionNone leaves the imports without extensions, which is the default with TargetTS
+	ImportExtensionNone = "none"
+	// Target is the parameter for the language of the generated files
+	Target = "target"
+	// TargetTS generates TypeScript sources, which is the default
+	TargetTS = "ts"
+	// TargetJS generates ES module JavaScript files along with the TypeScript declaration files, e.g. foo.pb.js and foo.pb.d.ts
+	TargetJS = "js"
+	// EnableStylingCheck is the parameter to format the generated files for the styling checks, it is read by the generator
+	EnableStylingCheck = "enable_styling_check"
+	// LintStrict is the parameter to turn the lint issues into generation errors, it is read by the generator
+	LintStrict = "lint_strict"
+	// ConfigFile is the parameter for the path to the YAML or JSON file with the parameters
+	ConfigFile = "config"
+	// ImportMappingPrefix is the prefix of the parameters mapping proto files to import specifiers, e.g. Mgoogle/api/http.proto=@googleapis/api/http,
+	// a directory ending with / maps all the files under it, e.g. Mgoogle/api/=@googleapis/api maps google/api/http.proto to @googleapis/api/http.pb
+	ImportMappingPrefix = "M"
+	// DefaultGetOperationURL is the gateway path for google.longrunning.Operations.GetOperation defined in googleapis
+	DefaultGetOperationURL = "/v1/{name=operations/**}"
 )
 
 // Registry analyse generation request, spits out the data the the rendering process
@@ -37,6 +132,12 @@ type Registry struct {
 	// FilesToGenerate contains a list of actual file to generate, different from all the files from the request, some of which are import files
 	FilesToGenerate map[string]bool
 
+	// GenerateDependencies will cause the generator to generate the files the files to generate depend on transitively
+	GenerateDependencies bool
+
+	// DependenciesToGenerate contains the files not in the request to generate, but generated with GenerateDependencies
+	DependenciesToGenerate map[string]bool
+
 	// TSImportRoots represents the ts import root for the generator to figure out required import path, will default to cwd
 	TSImportRoots []string
 
@@ -49,6 +150,12 @@ type Registry struct {
 	// FetchModuleFilename is the filename for the fetch module
 	FetchModuleFilename string
 
+	// FetchModulePackage is the module specifier to import the fetch module from, the fetch module will not be generated if it's specified
+	FetchModulePackage string
+
+	// FetchModuleFeatures is the features to include in the fetch module, either used or all
+	FetchModuleFeatures string
+
 	// FetchModuleR is the alias for fetch module directory
 	FetchModuleDirectoryAlias string
 
@@ -57,73 +164,314 @@ type Registry struct {
 
 	// TSPackages stores the package name keyed by the TS file name
 	TSPackages map[string]string
+
+	// Paths is the layout of the generated files, either source_relative or import
+	Paths string
+
+	// Module is the prefix stripped from the generated file names
+	Module string
+
+	// FileSuffix replaces .proto in the generated file names
+	FileSuffix string
+
+	// ClientFileSuffix replaces .proto in the names of the client files the services are generated into, empty if the
+	// services are generated along with the types
+	ClientFileSuffix string
+
+	// GenerateIndexFiles will cause the generator to generate the index.ts files re-exporting the files of each package
+	GenerateIndexFiles bool
+
+	// ServiceStyle is how the services are rendered, one of class, functions or functions_and_class
+	ServiceStyle string
+
+	// ImportResolution is how the import specifiers are resolved, one of filesystem or hermetic
+	ImportResolution string
+
+	// ImportExtension is appended to the imports between the generated files, empty for none
+	ImportExtension string
+
+	// Target is the language of the generated files, either ts or js
+	Target string
+
+	// Overrides are the parameters overridden per package or per file in the config file
+	Overrides []*Override
+
+	// TSPackagePrefixes stores the import specifier prefixes keyed by the proto directories mapped with the import mapping parameters
+	TSPackagePrefixes map[string]string
+
+	// FieldMaskDepth is how deep the field mask paths will be generated into nested messages, 0 means no field mask paths
+	FieldMaskDepth int
+
+	// GenerateUnboundMethods will cause the generator to generate methods without http rules with the synthesised path
+	GenerateUnboundMethods bool
+
+	// NestedTypeNaming is the strategy to name nested messages and enums, one of concat, underscore or namespace
+	NestedTypeNaming string
+
+	// ModuleIdentifierNaming is the scheme to name the imported modules, one of package_file, file or path
+	ModuleIdentifierNaming string
+
+	// HTTPRules stores the http rules from the gRPC API configuration keyed by the method selector
+	HTTPRules map[string]*annotations.HttpRule
+
+	// GetOperationURL is the gateway path of google.longrunning.Operations.GetOperation, it will be picked up from
+	// the request if google/longrunning/operations.proto is present, otherwise defaults to DefaultGetOperationURL
+	GetOperationURL string
+
+	// sourceLocations looks up the positions of the reported elements keyed by the proto file name
+	sourceLocations map[string]*sourceLocations
+
+	// collisionIssues are the identifier collisions found during the analysis to be reported by Lint,
+	// keyed by the name of the generated file
+	collisionIssues map[string][]*LintIssue
 }
 
-// NewRegistry initialise the registry and return the instance
+// NewRegistry initialise the registry from the parameters and return the instance
 func NewRegistry(paramsMap map[string]string) (*Registry, error) {
-	tsImportRoots, tsImportRootAliases, err := getTSImportRootInformation(paramsMap)
+	opts, err := ParseOptions(paramsMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRegistryFromOptions(*opts)
+}
+
+// NewRegistryFromOptions initialise the registry from the options and return the instance,
+// the options left empty take the defaults of the parameters
+func NewRegistryFromOptions(opts Options) (*Registry, error) {
+	tsImportRoots, tsImportRootAliases, err := getTSImportRootInformation(opts.TSImportRoots, opts.TSImportRootAliases)
 	log.Debugf("found ts import roots %v", tsImportRoots)
 	log.Debugf("found ts import root aliases %v", tsImportRootAliases)
 	if err != nil {
 		return nil, errors.Wrap(err, "error getting common import root information")
 	}
 
-	fetchModuleDirectory, fetchModuleFilename, err := getFetchModuleDirectory(paramsMap)
-	if err != nil {
-		return nil, errors.Wrap(err, "error getting fetch module directory")
+	fileSuffix := opts.FileSuffix
+	if fileSuffix == "" {
+		fileSuffix = paramDefault(FileSuffix)
+	}
+
+	fetchModuleDirectory := opts.FetchModuleDirectory
+	if fetchModuleDirectory == "" {
+		fetchModuleDirectory = paramDefault(FetchModuleDirectory)
+	}
+
+	fetchModuleFilename := opts.FetchModuleFilename
+	if fetchModuleFilename == "" {
+		fetchModuleFilename = "fetch" + fileSuffix
 	}
 	log.Debugf("found fetch module directory %s", fetchModuleDirectory)
 	log.Debugf("found fetch module name %s", fetchModuleFilename)
 
-	useProtoNames := false
+	fetchModulePackage := opts.FetchModulePackage
+	if fetchModulePackage != "" {
+		version := opts.FetchModulePackageVersion
+		if version == "" {
+			return nil, errors.Errorf("%s is required with %s, the fetch module API version of the generator is %s",
+				FetchModulePackageVersion, FetchModulePackage, FetchModuleAPIVersion)
+		}
 
-	useProtoNamesVal, ok := paramsMap[UseProtoNames]
-	if ok {
-		// default to true if not disabled specifi
-		useProtoNames = useProtoNamesVal == "true"
+		if version != FetchModuleAPIVersion {
+			return nil, errors.Errorf("fetch module API version %s of %s is incompatible with the generator, which requires %s",
+				version, fetchModulePackage, FetchModuleAPIVersion)
+		}
+		log.Debugf("found fetch module package %s", fetchModulePackage)
+	}
+
+	fetchModuleFeatures, err := paramValue(FetchModuleFeatures, opts.FetchModuleFeatures)
+	if err != nil {
+		return nil, err
+	}
+
+	nestedTypeNaming, err := paramValue(NestedTypeNaming, opts.NestedTypeNaming)
+	if err != nil {
+		return nil, err
+	}
+
+	moduleIdentifierNaming, err := paramValue(ModuleIdentifierNaming, opts.ModuleIdentifierNaming)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := paramValue(Paths, opts.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	importResolution, err := paramValue(ImportResolution, opts.ImportResolution)
+	if err != nil {
+		return nil, err
+	}
+	if importResolution == ImportResolutionHermetic && filepath.IsAbs(fetchModuleDirectory) {
+		return nil, errors.Errorf("%s needs to be relative to the output directory with %s %s", FetchModuleDirectory, ImportResolution, ImportResolutionHermetic)
+	}
+
+	clientFileSuffix := opts.ClientFileSuffix
+	if clientFileSuffix != "" && clientFileSuffix == fileSuffix {
+		return nil, errors.Errorf("invalid %s %s, it needs to be different from %s", ClientFileSuffix, clientFileSuffix, FileSuffix)
+	}
+
+	target, err := paramValue(Target, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	// the imports of the generated javascript files are resolved by the runtime, which doesn't look up the extension
+	importExtension := ""
+	if target == TargetJS {
+		importExtension = ".js"
+	}
+	if importExtensionVal := opts.ImportExtension; importExtensionVal != "" {
+		if err := validateParam(ImportExtension, importExtensionVal); err != nil {
+			return nil, err
+		}
+		importExtension = importExtensionVal
+		if importExtensionVal == ImportExtensionNone {
+			importExtension = ""
+		}
+	}
+
+	switch target {
+	case TargetJS:
+		// the javascript and the declaration file names are derived from the typescript ones
+		fileNames := [][2]string{{FileSuffix, fileSuffix}, {ClientFileSuffix, clientFileSuffix}, {FetchModuleFileName, fetchModuleFilename}}
+		for _, fileName := range fileNames {
+			if fileName[1] != "" && !strings.HasSuffix(fileName[1], ".ts") {
+				return nil, errors.Errorf("%s %s needs to end with .ts with %s %s", fileName[0], fileName[1], Target, TargetJS)
+			}
+		}
+		if opts.ImportExtension == ".ts" || opts.ImportExtension == ImportExtensionNone {
+			return nil, errors.Errorf("%s %s cannot be used with %s %s", ImportExtension, opts.ImportExtension, Target, TargetJS)
+		}
+	default:
+		// the typescript files compile into .js files, only the javascript files can be generated as .mjs
+		if importExtension == ".mjs" {
+			return nil, errors.Errorf("%s %s needs %s %s, which generates the .mjs files", ImportExtension, importExtension, Target, TargetJS)
+		}
+	}
+
+	tsPackages, tsPackagePrefixes, err := getImportMappings(opts.ImportMappings)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting import mappings")
+	}
+
+	httpRules := make(map[string]*annotations.HttpRule)
+	if apiConfig := opts.GrpcAPIConfiguration; apiConfig != "" {
+		httpRules, err = loadGrpcAPIConfiguration(apiConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "error loading gRPC API configuration")
+		}
+		log.Debugf("found %d http rules in %s", len(httpRules), apiConfig)
 	}
 
 	r := &Registry{
-		Types:                make(map[string]*TypeInformation),
-		TSImportRoots:        tsImportRoots,
-		TSImportRootAliases:  tsImportRootAliases,
-		FetchModuleDirectory: fetchModuleDirectory,
-		FetchModuleFilename:  fetchModuleFilename,
-		UseProtoNames:        useProtoNames,
-		TSPackages:           make(map[string]string),
+		Types:                  make(map[string]*TypeInformation),
+		TSImportRoots:          tsImportRoots,
+		TSImportRootAliases:    tsImportRootAliases,
+		FetchModuleDirectory:   fetchModuleDirectory,
+		FetchModuleFilename:    fetchModuleFilename,
+		FetchModulePackage:     fetchModulePackage,
+		FetchModuleFeatures:    fetchModuleFeatures,
+		HTTPRules:              httpRules,
+		GenerateUnboundMethods: !opts.OmitUnboundMethods,
+		NestedTypeNaming:       nestedTypeNaming,
+		ModuleIdentifierNaming: moduleIdentifierNaming,
+		TSPackages:             tsPackages,
+		TSPackagePrefixes:      tsPackagePrefixes,
+		ImportResolution:       importResolution,
+		UseProtoNames:          opts.UseProtoNames,
+		ServiceStyle:           paramDefault(ServiceStyle),
+		GenerateIndexFiles:     opts.GenerateIndexFiles,
+		GenerateDependencies:   opts.GenerateDependencies,
+		DependenciesToGenerate: make(map[string]bool),
+		ImportExtension:        importExtension,
+		Paths:                  paths,
+		Module:                 opts.Module,
+		FileSuffix:             fileSuffix,
+		ClientFileSuffix:       clientFileSuffix,
+		Target:                 target,
+		Overrides:              opts.Overrides,
+		GetOperationURL:        DefaultGetOperationURL,
+	}
+
+	if opts.ServiceStyle != "" {
+		if err := validateParam(ServiceStyle, opts.ServiceStyle); err != nil {
+			return nil, err
+		}
+		r.ServiceStyle = opts.ServiceStyle
+	}
+
+	if opts.FieldMaskDepth < 0 {
+		return nil, errors.Errorf("invalid %s %d, it needs to be a non negative integer", FieldMaskDepth, opts.FieldMaskDepth)
+	}
+	r.FieldMaskDepth = opts.FieldMaskDepth
+
+	for _, o := range opts.Overrides {
+		if err := validateOverride(o); err != nil {
+			return nil, err
+		}
 	}
 
 	return r, nil
 }
 
-func getFetchModuleDirectory(paramsMap map[string]string) (fetchModuleDirectory string, fetchModuleFile string, err error) {
-	fetchModuleDirectory, ok := paramsMap[FetchModuleDirectory]
+// setFileParams sets the parameters which only affect the files they are applied to, they are set from the
+// parameters at first, and can be overridden per package or per file in the config file
+func (r *Registry) setFileParams(paramsMap map[string]string) error {
+	keys := make([]string, 0, len(paramsMap))
+	for key := range paramsMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !isOverridableParam(key) {
+			return errors.Errorf("%s cannot be overridden per package or file, it needs to be one of %s", key, strings.Join(overridableParams, ", "))
+		}
+	}
+
+	if useProtoNamesVal, ok := paramsMap[UseProtoNames]; ok {
+		r.UseProtoNames = useProtoNamesVal == "true"
+	}
 
-	if !ok {
-		fetchModuleDirectory = "."
+	if serviceStyleVal, ok := paramsMap[ServiceStyle]; ok {
+		if err := validateParam(ServiceStyle, serviceStyleVal); err != nil {
+			return err
+		}
+		r.ServiceStyle = serviceStyleVal
 	}
 
-	fetchModuleFile, ok = paramsMap[FetchModuleFileName]
-	if !ok {
-		fetchModuleFile = "fetch.pb.ts"
+	if fieldMaskDepthVal, ok := paramsMap[FieldMaskDepth]; ok {
+		fieldMaskDepth, err := parseFieldMaskDepth(fieldMaskDepthVal)
+		if err != nil {
+			return err
+		}
+		r.FieldMaskDepth = fieldMaskDepth
 	}
 
-	return fetchModuleDirectory, fetchModuleFile, nil
+	return nil
 }
 
-func getTSImportRootInformation(paramsMap map[string]string) ([]string, []string, error) {
-	tsImportRootsValue, ok := paramsMap[TSImportRootParamsKey]
+// parseFieldMaskDepth parses the value of field_mask_depth, which is shared by the parameters and the overrides
+func parseFieldMaskDepth(value string) (int, error) {
+	fieldMaskDepth, err := strconv.Atoi(value)
+	if err != nil || fieldMaskDepth < 0 {
+		return 0, errors.Errorf("invalid %s %s, it needs to be a non negative integer", FieldMaskDepth, value)
+	}
+
+	return fieldMaskDepth, nil
+}
 
-	if !ok {
-		tsImportRootsValue = "."
+func getTSImportRootInformation(importRoots, importRootAliases []string) ([]string, []string, error) {
+	if len(importRoots) == 0 {
+		importRoots = strings.Split(paramDefault(TSImportRootParamsKey), TSImportRootSeparator)
 	}
 
-	splittedImportRoots := strings.Split(tsImportRootsValue, TSImportRootSeparator)
-	numImportRoots := len(splittedImportRoots)
+	numImportRoots := len(importRoots)
 
 	tsImportRoots := make([]string, 0, numImportRoots)
 
-	for _, r := range splittedImportRoots {
+	for _, r := range importRoots {
 		tsImportRoot := r
 		if !path.IsAbs(tsImportRoot) {
 			absPath, err := filepath.Abs(tsImportRoot)
@@ -137,17 +485,9 @@ func getTSImportRootInformation(paramsMap map[string]string) ([]string, []string
 		tsImportRoots = append(tsImportRoots, tsImportRoot)
 	}
 
-	tsImportRootAliasValue, ok := paramsMap[TSImportRootAliasParamsKey]
-
-	if !ok {
-		tsImportRootAliasValue = ""
-	}
-
-	splittedImportRootAliases := strings.Split(tsImportRootAliasValue, TSImportRootSeparator)
-
 	tsImportRootAliases := make([]string, numImportRoots)
 
-	for i, ra := range splittedImportRootAliases {
+	for i, ra := range importRootAliases {
 		if i >= numImportRoots {
 			// in case we have more root alias than root, we will just take the number matches the roots
 			break
@@ -179,6 +519,8 @@ type TypeInformation struct {
 	KeyType *data.MapEntryType
 	// Value type is the type information for the map value
 	ValueType *data.MapEntryType
+	// Message is the rendering data of the message, it is nil for enums, services and map entries
+	Message *data.Message
 }
 
 // IsFileToGenerate contains the file to be generated in the request
@@ -197,8 +539,10 @@ func (r *Registry) Analyse(req *plugin.CodeGeneratorRequest) (map[string]*data.F
 	files := req.GetProtoFile()
 	log.Debugf("about to start anaylyse files, %d in total", len(files))
 	data := make(map[string]*data.File)
+	r.sourceLocations = make(map[string]*sourceLocations, len(files))
 	// analyse all files in the request first
 	for _, f := range files {
+		r.sourceLocations[f.GetName()] = newSourceLocations(f.GetSourceCodeInfo())
 		fileData, err := r.analyseFile(f)
 		if err != nil {
 			return nil, errors.Wrapf(err, "error analysing file %s", *f.Name)
@@ -213,12 +557,63 @@ func (r *Registry) Analyse(req *plugin.CodeGeneratorRequest) (map[string]*data.F
 		return nil, errors.Wrap(err, "error collecting external dependency information after analysis finished")
 	}
 
+	if r.GenerateDependencies {
+		r.collectDependenciesToGenerate(data)
+	}
+
+	err = r.checkOutputFiles(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// the identifiers are checked once the imports are known, as the module identifiers are declared in the files too
+	err = r.checkIdentifierCollisions(data)
+	if err != nil {
+		return nil, err
+	}
+
 	return data, nil
 }
 
-// This simply just concats the parents name and the entity name.
+// getNameOfPackageLevelIdentifier joins the parents name and the entity name with the configured nested type naming strategy.
+// identifiers clashing with TypeScript reserved words or globals are escaped
 func (r *Registry) getNameOfPackageLevelIdentifier(parents []string, name string) string {
-	return strings.Join(parents, "") + name
+	switch r.NestedTypeNaming {
+	case NestedTypeNamingUnderscore:
+		return data.EscapeIdentifier(strings.Join(append(parents[:len(parents):len(parents)], name), "_"))
+	case NestedTypeNamingNamespace:
+		return strings.Join(escapeIdentifiers(append(parents[:len(parents):len(parents)], name)), ".")
+	default:
+		return data.EscapeIdentifier(strings.Join(parents, "") + name)
+	}
+}
+
+// escapeIdentifiers escapes each of the names to be declared in TypeScript
+func escapeIdentifiers(names []string) []string {
+	escaped := make([]string, 0, len(names))
+	for _, name := range names {
+		escaped = append(escaped, data.EscapeIdentifier(name))
+	}
+
+	return escaped
+}
+
+// getNamespace returns the TypeScript namespace the nested type will be rendered in, empty if it's rendered at the top level
+func (r *Registry) getNamespace(parents []string) string {
+	if r.NestedTypeNaming != NestedTypeNamingNamespace {
+		return ""
+	}
+
+	return strings.Join(escapeIdentifiers(parents), ".")
+}
+
+// getLocalName returns the name to declare the type with inside its namespace
+func (r *Registry) getLocalName(packageIdentifier, name string) string {
+	if r.NestedTypeNaming != NestedTypeNamingNamespace {
+		return packageIdentifier
+	}
+
+	return data.EscapeIdentifier(name)
 }
 
 func (r *Registry) getFullQualifiedName(packageName string, parents []string, name string) string {
@@ -238,6 +633,31 @@ func (r *Registry) getFullQualifiedName(packageName string, parents []string, na
 
 }
 
+// resolveTypeName resolves a type name referenced inside packageName in the same way protoc does,
+// it tries the innermost package scope first and walks outwards, returns empty string if it cannot be found
+func (r *Registry) resolveTypeName(packageName, name string) string {
+	if strings.HasPrefix(name, ".") {
+		if _, ok := r.Types[name]; ok {
+			return name
+		}
+		return ""
+	}
+
+	scopes := make([]string, 0)
+	if packageName != "" {
+		scopes = strings.Split(packageName, ".")
+	}
+
+	for i := len(scopes); i >= 0; i-- {
+		fqName := "." + strings.Join(append(scopes[:i:i], name), ".")
+		if _, ok := r.Types[fqName]; ok {
+			return fqName
+		}
+	}
+
+	return ""
+}
+
 func (r *Registry) isExternalDependenciesOutsidePackage(fqTypeName, packageName string) bool {
 	return strings.Index(fqTypeName, "."+packageName) != 0 && strings.Index(fqTypeName, ".") == 0
 }
@@ -312,10 +732,10 @@ func (r *Registry) getSourceFileForImport(source, target, root, alias string) (s
 		log.Debugf("no root alias found, trying to get the relative path for %s, result: %s", target, ret)
 	}
 
-	// remove .ts suffix if there's any
+	// replace .ts suffix with the import extension if there's any
 	suffixIndex := strings.LastIndex(ret, ".ts")
 	if suffixIndex != -1 {
-		ret = ret[0:suffixIndex]
+		ret = ret[0:suffixIndex] + r.ImportExtension
 	}
 
 	return ret, nil
@@ -323,69 +743,98 @@ func (r *Registry) getSourceFileForImport(source, target, root, alias string) (s
 }
 
 func (r *Registry) collectExternalDependenciesFromData(filesData map[string]*data.File) error {
-	for _, fileData := range filesData {
-		log.Debugf("collecting dependencies information for %s", fileData.TSFileName)
-		// dependency group up the dependency by package+file
-		dependencies := make(map[string]*data.Dependency)
-		for _, typeName := range fileData.ExternalDependingTypes {
-			typeInfo, ok := r.Types[typeName]
-			if !ok {
-				return errors.Errorf("cannot find type info for %s, $v", typeName)
+	for _, f := range filesData {
+		for _, fileData := range f.OutputFiles() {
+			err := r.collectExternalDependencies(fileData)
+			if err != nil {
+				return err
 			}
-			identifier := typeInfo.Package + "|" + typeInfo.File
-
-			if _, ok := dependencies[identifier]; !ok {
-				// only fill in if this file has not been mentioned before.
-				// the way import in the genrated file works is like
-				// import * as [ModuleIdentifier] from '[Source File]'
-				// so there only needs to be added once.
-				// Referencing types will be [ModuleIdentifier].[PackageIdentifier]
-				base := fileData.TSFileName
-				target := data.GetTSFileName(typeInfo.File)
-				sourceFile := ""
-				if pkg, ok := r.TSPackages[target]; ok {
-					log.Debugf("package import override %s has been found for file %s", pkg, target)
-					sourceFile = pkg
-				} else {
-					foundAtRoot, alias, err := r.findRootAliasForPath(func(absRoot string) (bool, error) {
-						completePath := filepath.Join(absRoot, typeInfo.File)
-						_, err := os.Stat(completePath)
-						if err != nil {
-							if os.IsNotExist(err) {
-								return false, nil
-							}
-
-							return false, err
-
-						} else {
-							return true, nil
-						}
+		}
+	}
 
-					})
+	return nil
+}
+
+// collectExternalDependencies turns the external types the file depends on into the imports of the file
+func (r *Registry) collectExternalDependencies(fileData *data.File) error {
+	log.Debugf("collecting dependencies information for %s", fileData.TSFileName)
+	// dependency group up the dependency by package+file
+	dependencies := make(map[string]*data.Dependency)
+	dependencyTypes := make(map[string]*TypeInformation)
+	for _, typeName := range fileData.ExternalDependingTypes {
+		typeInfo, ok := r.Types[typeName]
+		if !ok {
+			return errors.Errorf("cannot find type info for %s, $v", typeName)
+		}
+		identifier := typeInfo.Package + "|" + typeInfo.File
+
+		if _, ok := dependencies[identifier]; !ok {
+			// only fill in if this file has not been mentioned before.
+			// the way import in the genrated file works is like
+			// import * as [ModuleIdentifier] from '[Source File]'
+			// so there only needs to be added once.
+			// Referencing types will be [ModuleIdentifier].[PackageIdentifier]
+			base := fileData.TSFileName
+			target, err := r.getTSFileName(typeInfo.File, typeInfo.Package)
+			if err != nil {
+				return errors.Wrapf(err, "error getting the generated file name for import")
+			}
+			sourceFile := ""
+			if pkg, ok := r.getTSPackage(typeInfo.File); ok {
+				log.Debugf("package import override %s has been found for file %s", pkg, target)
+				sourceFile = pkg
+			} else if r.ImportResolution == ImportResolutionHermetic {
+				relativeImport, err := r.getRelativeImport(base, target)
+				if err != nil {
+					return errors.Wrap(err, "error getting source file for import")
+				}
+				sourceFile = relativeImport
+			} else {
+				foundAtRoot, alias, err := r.findRootAliasForPath(func(absRoot string) (bool, error) {
+					completePath := filepath.Join(absRoot, typeInfo.File)
+					_, err := os.Stat(completePath)
 					if err != nil {
-						return errors.WithStack(err)
-					}
+						if os.IsNotExist(err) {
+							return false, nil
+						}
 
-					if foundAtRoot != "" {
-						target = filepath.Join(foundAtRoot, target)
-					}
+						return false, err
 
-					sourceFile, err = r.getSourceFileForImport(base, target, foundAtRoot, alias)
-					if err != nil {
-						return errors.Wrap(err, "error getting source file for import")
+					} else {
+						return true, nil
 					}
+
+				})
+				if err != nil {
+					return errors.WithStack(err)
 				}
-				dependencies[identifier] = &data.Dependency{
-					ModuleIdentifier: data.GetModuleName(typeInfo.Package, typeInfo.File),
-					SourceFile:       sourceFile,
+
+				if foundAtRoot != "" {
+					target = filepath.Join(foundAtRoot, target)
 				}
+
+				sourceFile, err = r.getSourceFileForImport(base, target, foundAtRoot, alias)
+				if err != nil {
+					return errors.Wrap(err, "error getting source file for import")
+				}
+			}
+			dependencies[identifier] = &data.Dependency{
+				SourceFile: sourceFile,
+				TypeOnly:   true,
 			}
+			dependencyTypes[identifier] = typeInfo
 		}
 
-		for _, dependency := range dependencies {
-			fileData.Dependencies = append(fileData.Dependencies, dependency)
+		if typeInfo.ProtoType == descriptorpb.FieldDescriptorProto_TYPE_ENUM {
+			// enums are values in TypeScript, keep the value import for them
+			dependencies[identifier].TypeOnly = false
 		}
 	}
 
+	r.allocateModuleIdentifiers(fileData, dependencies, dependencyTypes)
+	for _, dependency := range dependencies {
+		fileData.Dependencies = append(fileData.Dependencies, dependency)
+	}
+
 	return nil
 }
@@ -0,0 +1,99 @@
+package registry
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	descriptorpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+// newTestRegistry returns a registry with the default options changed by the given function
+func newTestRegistry(t *testing.T, change func(opts *Options)) *Registry {
+	opts := DefaultOptions()
+	if change != nil {
+		change(&opts)
+	}
+
+	r, err := NewRegistryFromOptions(opts)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	return r
+}
+
+// loadFixture reads the file descriptor set in the text format from testdata, in the same order as protoc passes
+// them to the plugin. the files imported from outside the set, such as the google api annotations, are taken from
+// the descriptors linked into the test binary. the names of the files in the set are returned along with them
+func loadFixture(t *testing.T, fixture string) ([]*descriptorpb.FileDescriptorProto, []string) {
+	content, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	if !assert.NoError(t, prototext.Unmarshal(content, set)) {
+		t.FailNow()
+	}
+
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(set.File))
+	for _, f := range set.File {
+		seen[f.GetName()] = true
+		names = append(names, f.GetName())
+	}
+
+	files := make([]*descriptorpb.FileDescriptorProto, 0, len(set.File))
+	var addLinked func(fd protoreflect.FileDescriptor)
+	addLinked = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			addLinked(imports.Get(i).FileDescriptor)
+		}
+		files = append(files, protodesc.ToFileDescriptorProto(fd))
+	}
+
+	for _, f := range set.File {
+		for _, dependency := range f.GetDependency() {
+			if seen[dependency] {
+				continue
+			}
+
+			fd, err := protoregistry.GlobalFiles.FindFileByPath(dependency)
+			if !assert.NoError(t, err, "%s imported by %s", dependency, f.GetName()) {
+				t.FailNow()
+			}
+			addLinked(fd)
+		}
+	}
+
+	return append(files, set.File...), names
+}
+
+// analyseFixture analyses the files of the fixture with the registry, the files to generate default to the
+// files in the fixture
+func analyseFixture(t *testing.T, r *Registry, fixture string, filesToGenerate ...string) (map[string]*data.File, error) {
+	files, names := loadFixture(t, fixture)
+	if len(filesToGenerate) == 0 {
+		filesToGenerate = names
+	}
+
+	return r.Analyse(&plugin.CodeGeneratorRequest{
+		FileToGenerate: filesToGenerate,
+		ProtoFile:      files,
+	})
+}
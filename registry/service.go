@@ -2,12 +2,19 @@ package registry
 
 import (
 	"fmt"
+	"strings"
 
 	descriptorpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus" // nolint: depguard
 	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/longrunning"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/iancoleman/strcase"
+
 	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/options"
 )
 
 func getHTTPAnnotation(m *descriptorpb.MethodDescriptorProto) *annotations.HttpRule {
@@ -19,35 +26,53 @@ func hasHTTPAnnotation(m *descriptorpb.MethodDescriptorProto) bool {
 	return getHTTPAnnotation(m) != nil
 }
 
-func getHTTPMethodPath(m *descriptorpb.MethodDescriptorProto) (method, path string) {
-	if !hasHTTPAnnotation(m) {
-		return "", ""
+// getHTTPRule returns the http rule of the method either from the annotation or the gRPC API configuration.
+// it's an error to have both of them defined for the same method
+func (r *Registry) getHTTPRule(selector string, m *descriptorpb.MethodDescriptorProto) (*annotations.HttpRule, error) {
+	rule, hasExternalRule := r.HTTPRules[selector]
+	if hasHTTPAnnotation(m) {
+		if hasExternalRule {
+			return nil, errors.New("http rules found in both the annotation and the gRPC API configuration")
+		}
+
+		return getHTTPAnnotation(m), nil
+	}
+
+	return rule, nil
+}
+
+func getHTTPMethodPath(rule *annotations.HttpRule) (method, path string, err error) {
+	if rule == nil {
+		return "", "", nil
 	}
 
-	rule := getHTTPAnnotation(m)
 	pattern := rule.Pattern
 	switch pattern.(type) {
 	case *annotations.HttpRule_Get:
-		return "GET", rule.GetGet()
+		return "GET", rule.GetGet(), nil
 	case *annotations.HttpRule_Post:
-		return "POST", rule.GetPost()
+		return "POST", rule.GetPost(), nil
 	case *annotations.HttpRule_Put:
-		return "PUT", rule.GetPut()
+		return "PUT", rule.GetPut(), nil
 	case *annotations.HttpRule_Patch:
-		return "PATCH", rule.GetPatch()
+		return "PATCH", rule.GetPatch(), nil
 	case *annotations.HttpRule_Delete:
-		return "DELETE", rule.GetDelete()
+		return "DELETE", rule.GetDelete(), nil
+	case *annotations.HttpRule_Custom:
+		if rule.GetCustom().GetKind() == "" || rule.GetCustom().GetPath() == "" {
+			return "", "", errors.New("custom http rule needs both a kind and a path")
+		}
+		return strings.ToUpper(rule.GetCustom().GetKind()), rule.GetCustom().GetPath(), nil
 	default:
-		panic(fmt.Sprintf("unsupported HTTP method %T", pattern))
+		return "", "", errors.Errorf("unsupported HTTP method %T in the http rule", pattern)
 	}
 }
 
-func getHTTPBody(m *descriptorpb.MethodDescriptorProto) *string {
-	if !hasHTTPAnnotation(m) {
+func getHTTPBody(rule *annotations.HttpRule) *string {
+	if rule == nil {
 		return nil
 	}
 	empty := ""
-	rule := getHTTPAnnotation(m)
 	pattern := rule.Pattern
 	switch pattern.(type) {
 	case *annotations.HttpRule_Get:
@@ -58,9 +83,237 @@ func getHTTPBody(m *descriptorpb.MethodDescriptorProto) *string {
 	}
 }
 
-func (r *Registry) analyseService(fileData *data.File, packageName string, fileName string, service *descriptorpb.ServiceDescriptorProto) {
-	packageIdentifier := service.GetName()
-	fqName := "." + packageName + "." + packageIdentifier
+const (
+	// operationType is the fully qualified name of google.longrunning.Operation
+	operationType = ".google.longrunning.Operation"
+	// operationsServiceFQName is the fully qualified name of the google.longrunning.Operations service
+	operationsServiceFQName = ".google.longrunning.Operations"
+)
+
+func isServiceExcluded(s *descriptorpb.ServiceDescriptorProto) bool {
+	return proto.HasExtension(s.GetOptions(), options.E_TsExcludeService) &&
+		proto.GetExtension(s.GetOptions(), options.E_TsExcludeService).(bool)
+}
+
+func isMethodExcluded(m *descriptorpb.MethodDescriptorProto) bool {
+	return proto.HasExtension(m.GetOptions(), options.E_TsExcludeMethod) &&
+		proto.GetExtension(m.GetOptions(), options.E_TsExcludeMethod).(bool)
+}
+
+func getOperationInfo(m *descriptorpb.MethodDescriptorProto) *longrunning.OperationInfo {
+	option := proto.GetExtension(m.GetOptions(), longrunning.E_OperationInfo)
+	return option.(*longrunning.OperationInfo)
+}
+
+// analyseOperationInfo resolves the types declared in google.longrunning.operation_info for the method.
+// the types declared can be either fully qualified or relative to the package of the method
+func (r *Registry) analyseOperationInfo(fileData *data.File, packageName string, method *descriptorpb.MethodDescriptorProto) *data.LongRunningOperation {
+	if method.GetOutputType() != operationType {
+		return nil
+	}
+
+	info := getOperationInfo(method)
+	if info == nil {
+		return nil
+	}
+
+	resolveArgument := func(typeName string) *data.MethodArgument {
+		if typeName == "" {
+			return nil
+		}
+
+		fqTypeName := r.resolveTypeName(packageName, typeName)
+		if fqTypeName == "" {
+			log.Warnf("cannot resolve type %s in operation_info of %s, falling back to unknown", typeName, method.GetName())
+			return nil
+		}
+
+		arg := &data.MethodArgument{
+			Type:       fqTypeName,
+			IsExternal: r.isExternalDependenciesOutsidePackage(fqTypeName, packageName),
+		}
+
+		if arg.IsExternal {
+			fileData.ExternalDependingTypes = append(fileData.ExternalDependingTypes, fqTypeName)
+		}
+		fileData.TrackPackageNonScalarType(arg)
+
+		return arg
+	}
+
+	return &data.LongRunningOperation{
+		ResponseType:    resolveArgument(info.GetResponseType()),
+		MetadataType:    resolveArgument(info.GetMetadataType()),
+		GetOperationURL: r.GetOperationURL,
+	}
+}
+
+func getMethodSignatures(m *descriptorpb.MethodDescriptorProto) []string {
+	option := proto.GetExtension(m.GetOptions(), annotations.E_MethodSignature)
+	return option.([]string)
+}
+
+// signatureName names the convenience function of a method signature after the fields it takes,
+// e.g. signature "parent,book" of CreateBook will become CreateBookWithParentBook
+func signatureName(methodName string, params []*data.SignatureParam) string {
+	parts := []string{methodName, "With"}
+	for _, param := range params {
+		for _, p := range param.Path {
+			parts = append(parts, strcase.ToCamel(p))
+		}
+	}
+
+	return strings.Join(parts, "")
+}
+
+// signatureParamName names the parameter taking the field at the path inside the request
+func signatureParamName(path []string) string {
+	return data.EscapeIdentifier(strcase.ToLowerCamel(strings.Join(path, "_")))
+}
+
+// signatureFields returns the fields of the signature as they are declared in google.api.method_signature
+func signatureFields(signature *data.MethodSignature) string {
+	paths := make([]string, 0, len(signature.Params))
+	for _, param := range signature.Params {
+		paths = append(paths, strings.Join(param.Path, "."))
+	}
+
+	return strings.Join(paths, ",")
+}
+
+// signatureReservedParams are the parameters the convenience functions take after the signature params
+var signatureReservedParams = map[string]bool{"entityNotifier": true, "initReq": true}
+
+// analyseMethodSignatures resolves the fields declared in google.api.method_signature against the request message.
+// signatures with fields that cannot be found in the request will be skipped, while the ones with params ending up
+// with the same name are an error, e.g. a_b and a.b
+func (r *Registry) analyseMethodSignatures(fileData *data.File, packageName string, method *descriptorpb.MethodDescriptorProto) ([]*data.MethodSignature, error) {
+	signatures := make([]*data.MethodSignature, 0)
+	inputType, ok := r.Types[method.GetInputType()]
+	if !ok || inputType.Message == nil {
+		return signatures, nil
+	}
+
+	for _, signature := range getMethodSignatures(method) {
+		if signature == "" {
+			// an empty signature takes no field, which is no different to the full request method
+			continue
+		}
+
+		signatureData := &data.MethodSignature{}
+		paramPaths := make(map[string]string)
+		for _, fieldPath := range strings.Split(signature, ",") {
+			fieldPath = strings.TrimSpace(fieldPath)
+			path := strings.Split(fieldPath, ".")
+			field := r.findFieldByPath(inputType.Message, path)
+			if field == nil {
+				log.Warnf("cannot find field %s in %s for method signature %q of %s, skipping", fieldPath, method.GetInputType(), signature, method.GetName())
+				signatureData = nil
+				break
+			}
+
+			name := signatureParamName(path)
+			if previous, ok := paramPaths[name]; ok {
+				return nil, errors.Errorf("fields %s and %s of method signature %q are both taken as parameter %s", previous, fieldPath, signature, name)
+			}
+			if signatureReservedParams[name] {
+				return nil, errors.Errorf("field %s of method signature %q is taken as parameter %s, which collides with the parameter of the same name of the generated function", fieldPath, signature, name)
+			}
+			paramPaths[name] = fieldPath
+
+			arg := &data.MethodArgument{
+				Type:       field.Type,
+				IsExternal: r.isExternalDependenciesOutsidePackage(field.Type, packageName),
+				IsRepeated: field.IsRepeated,
+			}
+
+			if arg.IsExternal {
+				fileData.ExternalDependingTypes = append(fileData.ExternalDependingTypes, field.Type)
+			}
+			fileData.TrackPackageNonScalarType(arg)
+
+			signatureData.Params = append(signatureData.Params, &data.SignatureParam{
+				Name: name,
+				Path: path,
+				Type: arg,
+			})
+		}
+
+		if signatureData != nil {
+			signatureData.Name = signatureName(data.EscapeStaticMemberName(method.GetName()), signatureData.Params)
+			signatures = append(signatures, signatureData)
+		}
+	}
+
+	return signatures, nil
+}
+
+// checkServiceMemberCollisions makes sure the functions generated along with the rpcs, the operation wrappers and
+// the method signature functions, don't take the name of another member of the service, e.g. signature "parent"
+// of CreateBook and an rpc named CreateBookWithParent
+func checkServiceMemberCollisions(serviceData *data.Service) error {
+	declared := make(map[string]string, len(serviceData.Methods))
+	for _, method := range serviceData.Methods {
+		declared[method.Name] = "rpc " + method.Name
+	}
+
+	declare := func(name, member string, location *data.Location) error {
+		if previous, ok := declared[name]; ok {
+			return newElementError(location.Element, location.Path,
+				errors.Errorf("%s is named %s, which collides with %s", member, name, previous))
+		}
+
+		declared[name] = member
+		return nil
+	}
+
+	for _, method := range serviceData.Methods {
+		if method.LongRunning != nil && !method.ServerStreaming {
+			if err := declare(method.Name+"Operation", "the operation wrapper of "+method.Name, method.Location); err != nil {
+				return err
+			}
+		}
+
+		for _, signature := range method.Signatures {
+			member := fmt.Sprintf("the function of method signature %q of %s", signatureFields(signature), method.Name)
+			if err := declare(signature.Name, member, method.Location); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// findFieldByPath walks down the message through the path of field names, returns nil if any of them cannot be found
+func (r *Registry) findFieldByPath(message *data.Message, path []string) *data.Field {
+	for i, name := range path {
+		var found *data.Field
+		for _, f := range message.Fields {
+			if f.Name == name {
+				found = f
+				break
+			}
+		}
+
+		if found == nil || i == len(path)-1 {
+			return found
+		}
+
+		typeInfo, ok := r.Types[found.Type]
+		if !ok || typeInfo.Message == nil {
+			return nil
+		}
+		message = typeInfo.Message
+	}
+
+	return nil
+}
+
+func (r *Registry) analyseService(fileData *data.File, packageName string, fileName string, path []int32, service *descriptorpb.ServiceDescriptorProto) error {
+	// only the TypeScript identifier is escaped, the fully qualified name stays the one in the proto
+	packageIdentifier := data.EscapeIdentifier(service.GetName())
+	fqName := "." + packageName + "." + service.GetName()
 
 	// register itself in the registry map
 	r.Types[fqName] = &TypeInformation{
@@ -71,13 +324,65 @@ func (r *Registry) analyseService(fileData *data.File, packageName string, fileN
 		LocalIdentifier:    service.GetName(),
 	}
 
+	if isServiceExcluded(service) {
+		log.Debugf("service %s has been excluded with ts_exclude_service, skipping", fqName)
+		return nil
+	}
+
 	serviceData := data.NewService()
-	serviceData.Name = service.GetName()
-	serviceURLPart := packageName + "." + serviceData.Name
+	serviceData.Name = packageIdentifier
+	serviceData.Location = newLocation(fileName, fqName, path)
+	serviceURLPart := packageName + "." + service.GetName()
+	isOperationsService := fqName == operationsServiceFQName
+
+	for i, method := range service.Method {
+		methodFQName := serviceURLPart + "." + method.GetName()
+		methodPath := childPath(path, serviceMethodPath, i)
 
-	for _, method := range service.Method {
 		// don't support client streaming, will ignore the client streaming method
 		if method.GetClientStreaming() {
+			serviceData.UnsupportedMethods = append(serviceData.UnsupportedMethods, &data.Method{
+				Name:            method.GetName(),
+				ClientStreaming: true,
+				ServerStreaming: method.GetServerStreaming(),
+				Location:        newLocation(fileName, methodFQName, methodPath),
+			})
+			continue
+		}
+		rule, err := r.getHTTPRule(methodFQName, method)
+		if err != nil {
+			return newElementError(methodFQName, methodPath, err)
+		}
+
+		httpMethod := "POST"
+		url := "/" + serviceURLPart + "/" + method.GetName()
+		if rule != nil {
+			hm, u, err := getHTTPMethodPath(rule)
+			if err != nil {
+				return newElementError(methodFQName, methodPath, err)
+			}
+
+			if hm != "" && u != "" {
+				httpMethod = hm
+				url = u
+			}
+		}
+		body := getHTTPBody(rule)
+
+		if isOperationsService && method.GetName() == "GetOperation" && rule != nil {
+			// files are analysed in topological order, so that the operations service
+			// will always be picked up ahead of the methods returning operations
+			log.Debugf("found GetOperation gateway path %s", url)
+			r.GetOperationURL = url
+		}
+
+		if isMethodExcluded(method) {
+			log.Debugf("method %s has been excluded with ts_exclude_method, skipping", method.GetName())
+			continue
+		}
+
+		if rule == nil && !r.GenerateUnboundMethods {
+			log.Debugf("method %s doesn't have http rule and generate_unbound_methods is turned off, skipping", method.GetName())
 			continue
 		}
 
@@ -95,19 +400,13 @@ func (r *Registry) analyseService(fileData *data.File, packageName string, fileN
 			fileData.ExternalDependingTypes = append(fileData.ExternalDependingTypes, outputTypeFQName)
 		}
 
-		httpMethod := "POST"
-		url := "/" + serviceURLPart + "/" + method.GetName()
-		if hasHTTPAnnotation(method) {
-			hm, u := getHTTPMethodPath(method)
-			if hm != "" && u != "" {
-				httpMethod = hm
-				url = u
-			}
+		signatures, err := r.analyseMethodSignatures(fileData, packageName, method)
+		if err != nil {
+			return newElementError(methodFQName, methodPath, err)
 		}
-		body := getHTTPBody(method)
 
 		methodData := &data.Method{
-			Name: method.GetName(),
+			Name: data.EscapeStaticMemberName(method.GetName()),
 			URL:  url,
 			Input: &data.MethodArgument{
 				Type:       inputTypeFQName,
@@ -121,6 +420,9 @@ func (r *Registry) analyseService(fileData *data.File, packageName string, fileN
 			ClientStreaming: method.GetClientStreaming(),
 			HTTPMethod:      httpMethod,
 			HTTPRequestBody: body,
+			LongRunning:     r.analyseOperationInfo(fileData, packageName, method),
+			Signatures:      signatures,
+			Location:        newLocation(fileName, methodFQName, methodPath),
 		}
 
 		fileData.TrackPackageNonScalarType(methodData.Input)
@@ -129,5 +431,11 @@ func (r *Registry) analyseService(fileData *data.File, packageName string, fileN
 		serviceData.Methods = append(serviceData.Methods, methodData)
 	}
 
+	if err := checkServiceMemberCollisions(serviceData); err != nil {
+		return err
+	}
+
 	fileData.Services = append(fileData.Services, serviceData)
+
+	return nil
 }
@@ -0,0 +1,158 @@
+package registry
+
+import (
+	"testing"
+
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grpc-ecosystem/protoc-gen-grpc-gateway-ts/data"
+)
+
+func TestAnalyseOperationInfo(t *testing.T) {
+	r := newTestRegistry(t, nil)
+	filesData, err := analyseFixture(t, r, "long_running.textpb")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	methods := filesData["library/library.proto"].Services[0].Methods
+	if !assert.Len(t, methods, 3) {
+		t.FailNow()
+	}
+
+	// the GetOperation path of google.longrunning.Operations is picked up from the imported operations.proto
+	assert.Equal(t, &data.LongRunningOperation{
+		ResponseType:    &data.MethodArgument{Type: ".library.Book"},
+		MetadataType:    &data.MethodArgument{Type: ".library.CreateBookMetadata"},
+		GetOperationURL: "/v1/{name=operations/**}",
+	}, methods[0].LongRunning)
+
+	// types outside the package are imported, and the ones that cannot be resolved become unknown
+	assert.Equal(t, &data.LongRunningOperation{
+		ResponseType:    &data.MethodArgument{Type: ".google.protobuf.Empty", IsExternal: true},
+		GetOperationURL: "/v1/{name=operations/**}",
+	}, methods[1].LongRunning)
+	assert.Contains(t, filesData["library/library.proto"].ExternalDependingTypes, ".google.protobuf.Empty")
+
+	assert.Nil(t, methods[2].LongRunning)
+}
+
+func TestAnalyseMethodSignatures(t *testing.T) {
+	tests := []struct {
+		name        string
+		signatures  []string
+		rename      string
+		expected    map[string][]string
+		expectedErr string
+	}{
+		{
+			name:       "signatures",
+			signatures: []string{"parent,book", "parent, book.title", "missing", ""},
+			expected: map[string][]string{
+				"CreateBookWithParentBook":      {"parent", "book"},
+				"CreateBookWithParentBookTitle": {"parent", "bookTitle"},
+			},
+		},
+		{
+			name:        "params with the same name",
+			signatures:  []string{"a_b,a.b"},
+			expectedErr: `library/library.proto:25:3: library.Library.CreateBook: fields a_b and a.b of method signature "a_b,a.b" are both taken as parameter aB`,
+		},
+		{
+			name:        "param named after the parameters of the function",
+			signatures:  []string{"parent,init_req"},
+			expectedErr: `library/library.proto:25:3: library.Library.CreateBook: field init_req of method signature "parent,init_req" is taken as parameter initReq, which collides with the parameter of the same name of the generated function`,
+		},
+		{
+			name:        "function named after an rpc",
+			signatures:  []string{"parent"},
+			expectedErr: `library/library.proto:25:3: library.Library.CreateBook: the function of method signature "parent" of CreateBook is named CreateBookWithParent, which collides with rpc CreateBookWithParent`,
+		},
+		{
+			name:        "rpc named after the operation wrapper",
+			rename:      "CreateBookOperation",
+			expectedErr: "library/library.proto:25:3: library.Library.CreateBook: the operation wrapper of CreateBook is named CreateBookOperation, which collides with rpc CreateBookOperation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, names := loadFixture(t, "method_signature.textpb")
+			service := files[len(files)-1].Service[0]
+			proto.SetExtension(service.Method[0].Options, annotations.E_MethodSignature, tt.signatures)
+			if tt.rename != "" {
+				service.Method[1].Name = proto.String(tt.rename)
+			}
+
+			r := newTestRegistry(t, nil)
+			filesData, err := r.Analyse(&plugin.CodeGeneratorRequest{FileToGenerate: names, ProtoFile: files})
+			if tt.expectedErr != "" {
+				var elementErr *ElementError
+				if assert.True(t, errors.As(err, &elementErr), "%v", err) {
+					assert.Equal(t, tt.expectedErr, elementErr.Error())
+				}
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			signatures := make(map[string][]string)
+			for _, signature := range filesData["library/library.proto"].Services[0].Methods[0].Signatures {
+				for _, param := range signature.Params {
+					signatures[signature.Name] = append(signatures[signature.Name], param.Name)
+				}
+			}
+			assert.Equal(t, tt.expected, signatures)
+		})
+	}
+}
+
+func TestAnalyseExcludedMethods(t *testing.T) {
+	tests := []struct {
+		name                   string
+		generateUnboundMethods bool
+		expected               map[string]string
+	}{
+		{
+			name:                   "unbound methods",
+			generateUnboundMethods: true,
+			expected:               map[string]string{"Get": "/v1/counter", "Increment": "/counter.CounterService/Increment"},
+		},
+		{
+			name:     "unbound methods left out",
+			expected: map[string]string{"Get": "/v1/counter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, func(opts *Options) {
+				opts.OmitUnboundMethods = !tt.generateUnboundMethods
+			})
+
+			filesData, err := analyseFixture(t, r, "exclude.textpb")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			// AdminService is left out with ts_exclude_service and Reset with ts_exclude_method
+			services := filesData["counter/counter.proto"].Services
+			if !assert.Len(t, services, 1) {
+				t.FailNow()
+			}
+			assert.Equal(t, "CounterService", services[0].Name)
+
+			methods := make(map[string]string)
+			for _, method := range services[0].Methods {
+				methods[method.Name] = method.URL
+			}
+			assert.Equal(t, tt.expected, methods)
+		})
+	}
+}